@@ -1,17 +1,22 @@
 package main
 
 import (
+	"flag"
 	"fmt"
-	"io"
 	"log/slog"
+	"maps"
 	"net/http"
+	"os"
 	"sync/atomic"
 	"time"
 
 	"github.com/bermr/api-golang-base/internal/config"
+	"github.com/bermr/api-golang-base/internal/featureflags"
 	"github.com/bermr/api-golang-base/internal/infra/server"
 	"github.com/bermr/api-golang-base/internal/middlewares"
+	"github.com/bermr/api-golang-base/internal/tools/clock"
 	"github.com/bermr/api-golang-base/internal/tools/logger"
+	"github.com/bermr/api-golang-base/internal/tools/my_logger"
 	"github.com/go-chi/chi/v5"
 )
 
@@ -24,23 +29,63 @@ import (
 
 func main() {
 	var isShuttingDown atomic.Bool
-	var loggerOutputStream io.Writer
 
 	var loggerMdw *middlewares.RequestLoggerMiddleware
 	var errorMdw *middlewares.ErrorMiddleware
 
-	config, err := config.LoadConfig()
+	configDir := flag.String("config-dir", "", "directory searched first for the local config files")
+	flag.Parse()
+
+	configWatcher, err := config.NewWatcher(config.WatcherOptions{
+		Load: config.LoadOptions{ConfigDir: *configDir},
+	})
 	if err != nil {
 		slog.Error(fmt.Sprintf("Error loading config: %v", err))
 		return
 	}
+	defer configWatcher.Close()
+
+	cfg := configWatcher.Current()
+
+	baseLogger := logger.GetLogger(cfg, logger.Outputs(cfg))
+	slog.SetDefault(baseLogger.GetBaseLogger())
+	slog.Info("config loaded", "sources", cfg.Sources)
+
+	// a fatal log exits without running the deferred calls
+	baseLogger.OnShutdown(configWatcher.Close)
+
+	configWatcher.Subscribe(func(prev, next *config.Config) {
+		if prev.LogLevel != next.LogLevel {
+			err := baseLogger.SetLevel(next.LogLevel)
+			if err != nil {
+				slog.Error("log level change failed", "err", err)
+			} else {
+				slog.Info("log level changed", "from", prev.LogLevel, "to", next.LogLevel)
+			}
+		}
+
+		if !maps.Equal(prev.LogModuleLevels, next.LogModuleLevels) {
+			err := baseLogger.SetModuleLevels(next.LogModuleLevels)
+			if err != nil {
+				slog.Error("log module levels change failed", "err", err)
+			} else {
+				slog.Info("log module levels changed", "from", prev.LogModuleLevels, "to", next.LogModuleLevels)
+			}
+		}
+	})
 
-	loggerOutputStream = logger.OutputStream(config)
-	slog.SetDefault(logger.GetLogger(config, loggerOutputStream).GetBaseLogger())
+	flags, err := featureflags.New(featureflags.Options{
+		Load: config.LoadOptions{ConfigDir: *configDir},
+	})
+	if err != nil {
+		slog.Warn("feature flags not loaded, every flag is off until the next refresh", "err", err)
+	}
+	defer flags.Close()
+	baseLogger.OnShutdown(flags.Close)
 
 	router := chi.NewRouter()
 
-	loggerMdw = middlewares.NewLoggerMiddleware(config, loggerOutputStream)
+	loggerMdw = middlewares.NewLoggerMiddleware(baseLogger, clock.New(cfg.Location))
 	errorMdw = middlewares.NewErrorMiddleware()
 
 	// scopes a log context for the current request
@@ -51,13 +96,25 @@ func main() {
 
 	router.Handle("GET /healthcheck", healthcheckHandler())
 
-	srv := server.New(config, router)
+	// admin endpoints, only served with an admin token configured
+	if cfg.AdminToken != "" {
+		adminMdw := middlewares.NewAdminAuthMiddleware(cfg.AdminToken)
+
+		router.Route("/admin", func(r chi.Router) {
+			r.Use(adminMdw.HandleRequest)
+			r.Handle("/log-levels", my_logger.NewLevelsHandler(baseLogger.Levels()))
+		})
+	}
+
+	srv := server.New(cfg, router)
 	go srv.Start()
 
 	srv.GracefulShutdown(&isShuttingDown)
 
-	if outStreamCloser, ok := loggerOutputStream.(io.Closer); ok {
-		outStreamCloser.Close()
+	// runs the shutdown hooks and flushes the log outputs
+	err = baseLogger.Shutdown()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "logger shutdown error: %v\n", err)
 	}
 }
 
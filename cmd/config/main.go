@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/bermr/api-golang-base/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+const usage = `Inspects the effective configuration, as loaded by the api.
+
+Usage:
+  config print [-env ENV] [-app NAME] [-config-dir DIR] [-format json|yaml] [-sources] [-resolve-secrets]
+  config diff -from ENV -to ENV [-app NAME] [-config-dir DIR] [-env-overrides]
+
+Secret fields are always redacted. diff compares the stored configs, without the APP_<FIELD>
+environment overrides unless -env-overrides is set, and exits with status 1 when they differ.
+`
+
+func main() {
+	var err error
+
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "print":
+		err = printCmd(os.Args[2:], os.Stdout)
+	case "diff":
+		err = diffCmd(os.Args[2:], os.Stdout)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func printCmd(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("print", flag.ExitOnError)
+	env := flags.String("env", os.Getenv("GO_ENV"), "environment to load")
+	appName := flags.String("app", os.Getenv("APP_NAME"), "application to load")
+	configDir := flags.String("config-dir", "", "directory searched first for the local config files")
+	format := flags.String("format", "json", "output format, json or yaml")
+	withSources := flags.Bool("sources", false, "include the layer that set each field")
+	resolveSecrets := flags.Bool("resolve-secrets", false, "resolve the secret references, failing if any can't be")
+	flags.Parse(args)
+
+	cfg, err := config.LoadConfig(config.LoadOptions{
+		Env:         *env,
+		AppName:     *appName,
+		ConfigDir:   *configDir,
+		SkipSecrets: !*resolveSecrets,
+	})
+	if err != nil {
+		return err
+	}
+
+	var output any = config.Dump(cfg)
+	if *withSources {
+		output = map[string]any{
+			"config":  output,
+			"sources": cfg.Sources,
+		}
+	}
+
+	return encode(out, *format, output)
+}
+
+func diffCmd(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	fromEnv := flags.String("from", "development", "environment to diff from")
+	toEnv := flags.String("to", "production", "environment to diff to")
+	appName := flags.String("app", os.Getenv("APP_NAME"), "application to load")
+	configDir := flags.String("config-dir", "", "directory searched first for the local config files")
+	envOverrides := flags.Bool("env-overrides", false, "apply the APP_<FIELD> environment overrides to both configs")
+	flags.Parse(args)
+
+	from, err := loadFlattened(*fromEnv, *appName, *configDir, *envOverrides)
+	if err != nil {
+		return fmt.Errorf("error loading %v config: %w", *fromEnv, err)
+	}
+
+	to, err := loadFlattened(*toEnv, *appName, *configDir, *envOverrides)
+	if err != nil {
+		return fmt.Errorf("error loading %v config: %w", *toEnv, err)
+	}
+
+	paths := make([]string, 0, len(from)+len(to))
+	for path := range from {
+		paths = append(paths, path)
+	}
+	for path := range to {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+	paths = slices.Compact(paths)
+
+	differences := 0
+	for _, path := range paths {
+		fromValue, inFrom := from[path]
+		toValue, inTo := to[path]
+
+		switch {
+		case !inTo:
+			fmt.Fprintf(out, "- %v: %v\n", path, fromValue)
+		case !inFrom:
+			fmt.Fprintf(out, "+ %v: %v\n", path, toValue)
+		case fromValue != toValue:
+			fmt.Fprintf(out, "~ %v: %v -> %v\n", path, fromValue, toValue)
+		default:
+			continue
+		}
+
+		differences++
+	}
+
+	if differences > 0 {
+		return fmt.Errorf("%v and %v configs differ in %v fields", *fromEnv, *toEnv, differences)
+	}
+
+	fmt.Fprintf(out, "%v and %v configs match\n", *fromEnv, *toEnv)
+
+	return nil
+}
+
+// loadFlattened loads the env config and flattens its dump into path -> value pairs
+func loadFlattened(env string, appName string, configDir string, envOverrides bool) (map[string]string, error) {
+	cfg, err := config.LoadConfig(config.LoadOptions{
+		Env:              env,
+		AppName:          appName,
+		ConfigDir:        configDir,
+		SkipSecrets:      true,
+		SkipEnvOverrides: !envOverrides,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	dump := config.Dump(cfg)
+
+	// always differs between environments
+	delete(dump, "Env")
+
+	flattened := make(map[string]string)
+	flatten("", dump, flattened)
+
+	return flattened, nil
+}
+
+func flatten(path string, value any, flattened map[string]string) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range v {
+			flatten(strings.TrimPrefix(path+"."+key, "."), child, flattened)
+		}
+	case []any:
+		for i, child := range v {
+			flatten(fmt.Sprintf("%v[%v]", path, i), child, flattened)
+		}
+	default:
+		flattened[path] = fmt.Sprint(v)
+	}
+}
+
+func encode(out io.Writer, format string, value any) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+
+	case "yaml":
+		encoder := yaml.NewEncoder(out)
+		encoder.SetIndent(2)
+		return encoder.Encode(value)
+
+	default:
+		return fmt.Errorf("format must be one of [json, yaml], got %q", format)
+	}
+}
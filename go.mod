@@ -5,9 +5,12 @@ go 1.23.0
 toolchain go1.23.4
 
 require (
+	github.com/BurntSushi/toml v1.5.0
 	github.com/aws/aws-sdk-go-v2 v1.36.3
 	github.com/aws/aws-sdk-go-v2/config v1.29.14
+	github.com/aws/aws-sdk-go-v2/service/secretsmanager v1.35.4
 	github.com/aws/aws-sdk-go-v2/service/ssm v1.59.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
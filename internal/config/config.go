@@ -3,142 +3,438 @@ package config
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"maps"
 	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/ssm"
 )
 
 type ParamPaths map[string]string
 type LoadedParams map[string]string
 
 type DbConnConfig struct {
-	Database string `json:"database"`
+	Database string `json:"database" validate:"required"`
 	Host     struct {
-		Read  []string `json:"read"`
-		Write string   `json:"write"`
+		Read  []string `json:"read" validate:"dive,hostname"`
+		Write string   `json:"write" validate:"required,hostname"`
 	} `json:"host"`
-	Port     string `json:"port"`
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Port     DbPort `json:"port" validate:"required,port"`
+	Username string `json:"username" validate:"required" secret:"true"`
+	Password string `json:"password" secret:"true" log:"redact"`
 }
 
 type Db map[string]DbConnConfig
 
+// DbPort is a port number, decoded from either a number or a string, e.g. 3306 or "3306"
+type DbPort string
+
+func (p *DbPort) UnmarshalJSON(data []byte) error {
+	var port json.Number
+
+	err := json.Unmarshal(data, &port)
+	if err != nil {
+		return fmt.Errorf("port must be a number or a string, got %s", data)
+	}
+
+	*p = DbPort(port)
+
+	return nil
+}
+
+// LogOutput is a log sink, with its own level and format, defaulting to the config ones
+type LogOutput struct {
+	Type   string  `json:"type" validate:"required,oneof=stdout stderr firehose file"`
+	Level  *string `json:"level" validate:"oneof=trace debug info warn error critical fatal"`
+	Format *string `json:"format" validate:"oneof=json text pretty"`
+}
+
+// LogFile is the rotating log file of the file outputs, reopened on SIGHUP
+type LogFile struct {
+	Path        string `json:"path" validate:"required"`
+	MaxSizeMB   *int   `json:"max_size_mb" validate:"min=1"`
+	RotateHours *int   `json:"rotate_hours" validate:"min=1"`
+	MaxBackups  *int   `json:"max_backups" validate:"min=0"`
+	Compress    bool   `json:"compress"`
+}
+
+// LogSampling samples the frequent log records, per level and message. Error and above
+// records are never sampled.
+type LogSampling struct {
+	IntervalMs *int                        `json:"interval_ms" validate:"min=1"`
+	Default    *LogSamplingRule            `json:"default"`
+	Levels     map[string]*LogSamplingRule `json:"levels"`
+	Messages   map[string]*LogSamplingRule `json:"messages"`
+}
+
+// LogSamplingRule logs the first records of each interval, then 1 in thereafter
+type LogSamplingRule struct {
+	First      int `json:"first" validate:"min=0"`
+	Thereafter int `json:"thereafter" validate:"min=0"`
+}
+
+// LogDedup collapses the repeated log records of each window into one, with a repeat count
+type LogDedup struct {
+	WindowMs *int    `json:"window_ms" validate:"min=1"`
+	Level    *string `json:"level" validate:"oneof=trace debug info warn error critical fatal"`
+}
+
 type Config struct {
-	AppName  string
-	Env      string
-	Timezone string
-	Port     int    `json:"port"`
-	LogLevel string `json:"log_level"`
-	Db       Db
-	// define the rest of the config as needed
+	AppName   string `validate:"required"`
+	Env       string `validate:"required"`
+	Timezone  string `validate:"timezone"`
+	Port      int    `json:"port" validate:"min=1,max=65535"`
+	LogLevel  string `json:"log_level" validate:"required,oneof=trace debug info warn error critical fatal"`
+	LogFormat string `json:"log_format" validate:"required,oneof=json text pretty"`
+	Db        Db
+
+	// Log sinks, the log file if set, else stdout in development and firehose otherwise
+	LogOutputs []LogOutput `json:"log_outputs"`
+	LogFile    *LogFile    `json:"log_file"`
+
+	// Log sampling and deduplication, off if unset
+	LogSampling *LogSampling `json:"log_sampling"`
+	LogDedup    *LogDedup    `json:"log_dedup"`
+
+	// Minimum log level per logger module, overriding LogLevel
+	LogModuleLevels map[string]string `json:"log_module_levels" validate:"dive,oneof=trace debug info warn error critical fatal"`
+
+	// Bearer token of the admin endpoints, which are off if empty
+	AdminToken string `json:"admin_token" secret:"true" log:"redact"`
+	// modules declare the rest of the config as sections, see Register
+
+	// Timezone location, UTC if no timezone is set
+	Location *time.Location `json:"-"`
+
+	// Layer that set each field: default, option, the loaded param path or env
+	Sources ConfigSources `json:"-"`
+
+	sections map[string]any
 }
 
-func LoadConfig() (*Config, error) {
+// Log levels that can have their own sampling rule, see LogSampling
+var (
+	sampledLogLevels   = []string{"trace", "debug", "info", "warn"}
+	unsampledLogLevels = []string{"error", "critical", "fatal"}
+)
+
+// Default max time to load the config
+const default_load_ms_timeout = 30 * 1000
+
+type LoadOptions struct {
+	// Context bounding the load, defaults to context.Background()
+	Context context.Context
+
+	// Environment to load, defaults to GO_ENV
+	Env string
+
+	// Application to load, defaults to APP_NAME
+	AppName string
+
+	// Max time to load the config
+	Timeout *int
+
+	// Directory searched first for local config files, defaults to CONFIG_DIR
+	ConfigDir string
+
+	// Source to load the config from, defaults to the source named by CONFIG_SOURCE or the
+	// default source for Env
+	Source ParamSource
+
+	// AWS config used by the SSM source, defaults to the shared AWS config
+	AWSConfig *aws.Config
+
+	// Custom AWS API endpoint used by the SSM source, e.g. a LocalStack URL
+	AWSEndpoint string
+
+	// Client used by the SSM source, e.g. a mock. Takes precedence over AWSConfig.
+	SSMClient SSMClient
+
+	// Param tag naming the format of the SSM params, see SSMParamSource.FormatTag
+	SSMFormatTag string
+
+	// Resolver of the secret references in the fields tagged `secret:"true"`, defaults to one
+	// using the AWS options above. Reuse it across loads to keep its cache.
+	Secrets *SecretResolver
+
+	// Leave the secret references unresolved, e.g. to inspect the config without secret access
+	SkipSecrets bool
+
+	// Ignore the APP_<FIELD> environment overrides, e.g. to compare the stored configs
+	SkipEnvOverrides bool
+
+	// Layers Env and AppName came from, set by Resolve
+	envSource     string
+	appNameSource string
+}
+
+// LoadConfig loads the config from opts.Source or, if not set, from the param source named
+// by CONFIG_SOURCE, falling back to the default source for GO_ENV
+func LoadConfig(opts LoadOptions) (*Config, error) {
+	err := opts.Resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(opts.Context, time.Millisecond*time.Duration(*opts.Timeout))
+	defer cancel()
+
+	return loadConfig(ctx, opts)
+}
+
+func loadConfig(ctx context.Context, opts LoadOptions) (*Config, error) {
 	var loadedParams LoadedParams
 	var config Config
 	var db Db
 	var err error
 
-	env := os.Getenv("GO_ENV")
-	appName := os.Getenv("APP_NAME")
+	env := opts.Env
+	appName := opts.AppName
 	paramPaths := buildParamPaths(env, appName)
 
-	switch env {
-	case "testing":
-	case "production":
-		loadedParams, err = loadSSMParams(paramPaths)
+	loadedParams, err = opts.Source.Load(ctx, paramPaths)
+	if err != nil {
+		return nil, err
+	}
+
+	configJson, err := normalizePayload(paramPaths["CONFIG_PATH"], loadedParams[paramPaths["CONFIG_PATH"]])
+	if err != nil {
+		return nil, err
+	}
+
+	databasesJson, err := normalizePayload(paramPaths["DATABASES_PATH"], loadedParams[paramPaths["DATABASES_PATH"]])
+	if err != nil {
+		return nil, err
+	}
+
+	timezone := loadedParams[paramPaths["TIMEZONE_PATH"]]
+
+	applyDefaultsLayer(&config)
+
+	err = applyJsonLayer(&config, configJson, paramPaths["CONFIG_PATH"])
+	if err != nil {
+		return nil, fmt.Errorf("error decoding %v: %w", paramPaths["CONFIG_PATH"], err)
+	}
+
+	if !opts.SkipEnvOverrides {
+		err = applyEnvLayer(&config)
 		if err != nil {
 			return nil, err
 		}
+	}
 
-	case "development":
-		loadedParams, err = loadLocalParams(paramPaths)
+	if databasesJson != "" {
+		err = json.Unmarshal([]byte(databasesJson), &db)
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("error decoding %v: %w", paramPaths["DATABASES_PATH"], err)
+		}
+	}
+
+	config.AppName = appName
+	config.Env = env
+	config.Timezone = timezone
+	config.Db = db
+
+	config.Sources[sourceAppName] = opts.appNameSource
+	config.Sources[sourceEnv] = opts.envSource
+	config.Sources[sourceTimezone] = layerDefault
+	if timezone != "" {
+		config.Sources[sourceTimezone] = paramPaths["TIMEZONE_PATH"]
+	}
+	config.Sources[sourceDb] = layerDefault
+	if databasesJson != "" {
+		config.Sources[sourceDb] = paramPaths["DATABASES_PATH"]
+	}
+
+	var resolveSecrets func(v any) error
+	if !opts.SkipSecrets {
+		resolveSecrets = func(v any) error {
+			return opts.Secrets.resolveSecrets(ctx, v)
 		}
 
-	default:
-		return nil, errors.New("env must be one of [development, testing, production]")
+		err = resolveSecrets(&config)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	configJson := loadedParams[paramPaths["CONFIG_PATH"]]
-	databasesJson := loadedParams[paramPaths["DATABASES_PATH"]]
-	timezone := loadedParams[paramPaths["TIMEZONE_PATH"]]
+	var validationErrs ValidationErrors
+	validateAt(&config, "", &validationErrs)
+	validateLogConfig(&config, &validationErrs)
 
-	err = json.Unmarshal([]byte(configJson), &config)
+	config.sections, err = decodeSections(configJson, paramPaths["CONFIG_PATH"], config.Sources, resolveSecrets, &validationErrs)
 	if err != nil {
 		return nil, err
 	}
 
-	err = json.Unmarshal([]byte(databasesJson), &db)
+	if len(validationErrs) > 0 {
+		return nil, validationErrs
+	}
+
+	config.Location, err = loadLocation(timezone)
 	if err != nil {
 		return nil, err
 	}
 
-	config.AppName = appName
-	config.Env = env
-	config.Timezone = timezone
-	config.Db = db
-
 	return &config, nil
 }
 
-func buildParamPaths(env, appName string) ParamPaths {
-	return ParamPaths{
-		"CONFIG_PATH":    fmt.Sprintf("/%v/%v/config", env, appName),
-		"DATABASES_PATH": fmt.Sprintf("/%v/%v/databases", env, appName),
-		"TIMEZONE_PATH":  fmt.Sprintf("/%v/common/timezone", env),
+// validateLogConfig checks what the struct tags of the log fields can't express: the
+// fields depending on each other and the sampling level keys
+func validateLogConfig(config *Config, errs *ValidationErrors) {
+	hasFileOutput := false
+
+	for i, output := range config.LogOutputs {
+		if output.Type != "file" {
+			continue
+		}
+
+		hasFileOutput = true
+		if config.LogFile == nil {
+			*errs = append(*errs, ValidationError{
+				Path:    fmt.Sprintf("log_outputs[%v]", i),
+				Rule:    "log_file",
+				Message: "file output requires log_file",
+			})
+		}
+	}
+
+	// without log_outputs, the log file is the only output
+	if config.LogFile != nil && len(config.LogOutputs) > 0 && !hasFileOutput {
+		*errs = append(*errs, ValidationError{
+			Path:    "log_file",
+			Rule:    "log_outputs",
+			Message: "is unused, no log_outputs entry is of type file",
+		})
+	}
+
+	if config.LogSampling == nil {
+		return
+	}
+
+	for _, level := range slices.Sorted(maps.Keys(config.LogSampling.Levels)) {
+		path := mapKeyPath("log_sampling.levels", reflect.ValueOf(level))
+
+		switch {
+		case slices.Contains(unsampledLogLevels, level):
+			*errs = append(*errs, ValidationError{Path: path, Rule: "oneof", Message: "error and above records are never sampled"})
+		case !slices.Contains(sampledLogLevels, level):
+			*errs = append(*errs, ValidationError{Path: path, Rule: "oneof", Message: fmt.Sprintf("must be one of [%v]", strings.Join(sampledLogLevels, ", "))})
+		}
 	}
 }
 
-func loadSSMParams(paramPaths ParamPaths) (loadedParams LoadedParams, err error) {
-	loadedParams = make(map[string]string, len(paramPaths))
-	paramNames := make([]string, 0, len(paramPaths))
+// Resolve fills the unset options with their defaults and hands them to the local and SSM
+// sources and the secret providers
+func (o *LoadOptions) Resolve() error {
+	var err error
 
-	for _, v := range paramPaths {
-		paramNames = append(paramNames, v)
+	if o.Context == nil {
+		o.Context = context.Background()
 	}
 
-	cfg, err := config.LoadDefaultConfig(context.TODO())
-	if err != nil {
-		return nil, err
+	if o.Env == "" {
+		o.Env = os.Getenv("GO_ENV")
+		o.envSource = fmt.Sprintf("%v:GO_ENV", layerEnv)
+	} else if o.envSource == "" {
+		o.envSource = layerOption
 	}
 
-	ssmClient := ssm.NewFromConfig(cfg)
-	ssmOutput, err := ssmClient.GetParameters(context.TODO(), &ssm.GetParametersInput{
-		Names:          paramNames,
-		WithDecryption: aws.Bool(true),
-	})
-	if err != nil {
-		return nil, err
+	if o.AppName == "" {
+		o.AppName = os.Getenv("APP_NAME")
+		o.appNameSource = fmt.Sprintf("%v:APP_NAME", layerEnv)
+	} else if o.appNameSource == "" {
+		o.appNameSource = layerOption
+	}
+
+	if o.Timeout == nil {
+		defaultTimeout := default_load_ms_timeout
+		o.Timeout = &defaultTimeout
+	}
+
+	if o.Source == nil {
+		o.Source, err = NewParamSource(os.Getenv("CONFIG_SOURCE"), o.Env)
+		if err != nil {
+			return err
+		}
+	}
+
+	if localSource, ok := o.Source.(*LocalParamSource); ok {
+		if localSource.Env == "" {
+			localSource.Env = o.Env
+		}
+		if localSource.AppName == "" {
+			localSource.AppName = o.AppName
+		}
+		if localSource.Dirs == nil && o.ConfigDir != "" {
+			localSource.Dirs = append([]string{o.ConfigDir}, localSource.SearchDirs()...)
+		}
+	}
+
+	if ssmSource, ok := o.Source.(*SSMParamSource); ok {
+		if ssmSource.Client == nil {
+			ssmSource.Client = o.SSMClient
+		}
+		if ssmSource.AWSConfig == nil {
+			ssmSource.AWSConfig = o.AWSConfig
+		}
+		if ssmSource.FormatTag == "" {
+			ssmSource.FormatTag = o.SSMFormatTag
+		}
+		if ssmSource.Endpoint == "" {
+			ssmSource.Endpoint = o.AWSEndpoint
+		}
 	}
 
-	for _, param := range ssmOutput.Parameters {
-		loadedParams[*param.Name] = *param.Value
+	if o.Secrets == nil {
+		defaultSecretProvider := os.Getenv("SECRETS_PROVIDER")
+		if defaultSecretProvider == "" {
+			defaultSecretProvider = defaultSecretProviders[o.Env]
+		}
+
+		o.Secrets = NewSecretResolver(SecretResolverOptions{
+			DefaultProvider: defaultSecretProvider,
+			Providers: map[string]SecretProvider{
+				"secretsmanager": &SecretsManagerProvider{AWSConfig: o.AWSConfig, Endpoint: o.AWSEndpoint},
+				"ssm":            &SSMSecretProvider{Client: o.SSMClient, AWSConfig: o.AWSConfig, Endpoint: o.AWSEndpoint},
+				"file":           &FileSecretProvider{},
+			},
+		})
 	}
 
-	return loadedParams, nil
+	return nil
 }
 
-func loadLocalParams(paramPaths ParamPaths) (loadedParams LoadedParams, err error) {
-	configJson, err := os.ReadFile("./internal/config/.config.json")
-	if err != nil {
-		return nil, err
+// Loaded locations are cached so every config snapshot shares the same *time.Location
+var locations sync.Map
+
+func loadLocation(timezone string) (*time.Location, error) {
+	if location, ok := locations.Load(timezone); ok {
+		return location.(*time.Location), nil
 	}
 
-	databasesJson, err := os.ReadFile("./internal/config/.databases.json")
+	location, err := time.LoadLocation(timezone)
 	if err != nil {
 		return nil, err
 	}
 
-	return LoadedParams{
-		paramPaths["CONFIG_PATH"]:    string(configJson),
-		paramPaths["DATABASES_PATH"]: string(databasesJson),
-		paramPaths["TIMEZONE_PATH"]:  os.Getenv("TIMEZONE"),
-	}, nil
+	locations.Store(timezone, location)
+
+	return location, nil
+}
+
+// Param path keys a source may not have, e.g. without a timezone the config uses UTC
+var optionalParamKeys = []string{"TIMEZONE_PATH"}
+
+func buildParamPaths(env, appName string) ParamPaths {
+	return ParamPaths{
+		"CONFIG_PATH":    fmt.Sprintf("/%v/%v/config", env, appName),
+		"DATABASES_PATH": fmt.Sprintf("/%v/%v/databases", env, appName),
+		"TIMEZONE_PATH":  fmt.Sprintf("/%v/common/timezone", env),
+	}
 }
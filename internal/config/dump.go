@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Replaces the value of the fields tagged `secret:"true"` in config dumps
+const redacted_value = "[REDACTED]"
+
+// Dump returns the effective config, including the registered sections, as a json-like map
+// with every field tagged `secret:"true"` redacted
+func Dump(c *Config) map[string]any {
+	dump, _ := dumpValue(reflect.ValueOf(*c)).(map[string]any)
+
+	for name, section := range c.sections {
+		dump[name] = dumpValue(reflect.ValueOf(section))
+	}
+
+	return dump
+}
+
+func dumpValue(value reflect.Value) any {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return nil
+		}
+		return dumpValue(value.Elem())
+
+	case reflect.Struct:
+		valueType := value.Type()
+		dump := make(map[string]any, valueType.NumField())
+
+		for i := range valueType.NumField() {
+			field := valueType.Field(i)
+			if !field.IsExported() || field.Tag.Get("json") == "-" {
+				continue
+			}
+
+			if field.Tag.Get("secret") == "true" && !value.Field(i).IsZero() {
+				dump[fieldName(field)] = redacted_value
+				continue
+			}
+
+			dump[fieldName(field)] = dumpValue(value.Field(i))
+		}
+
+		return dump
+
+	case reflect.Map:
+		dump := make(map[string]any, value.Len())
+		for _, key := range value.MapKeys() {
+			dump[fmt.Sprint(key.Interface())] = dumpValue(value.MapIndex(key))
+		}
+
+		return dump
+
+	case reflect.Slice, reflect.Array:
+		dump := make([]any, 0, value.Len())
+		for i := range value.Len() {
+			dump = append(dump, dumpValue(value.Index(i)))
+		}
+
+		return dump
+
+	default:
+		return value.Interface()
+	}
+}
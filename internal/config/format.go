@@ -0,0 +1,118 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Format of a config payload. Every payload is normalized to JSON before being decoded.
+type Format string
+
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+	FormatTOML Format = "toml"
+)
+
+var formatsByExtension = map[string]Format{
+	".json": FormatJSON,
+	".yaml": FormatYAML,
+	".yml":  FormatYAML,
+	".toml": FormatTOML,
+}
+
+// FormatFromExtension returns the format of a file by its extension
+func FormatFromExtension(fileName string) (Format, bool) {
+	format, ok := formatsByExtension[strings.ToLower(filepath.Ext(fileName))]
+	return format, ok
+}
+
+// SniffFormat guesses the format of a payload by parsing it, trying JSON, then TOML and
+// falling back to YAML. Prefer an explicit format when one is known.
+func SniffFormat(payload string) Format {
+	var decoded map[string]any
+
+	if json.Valid([]byte(payload)) {
+		return FormatJSON
+	}
+
+	if toml.Unmarshal([]byte(payload), &decoded) == nil {
+		return FormatTOML
+	}
+
+	return FormatYAML
+}
+
+// toJson converts a payload in format to JSON
+func toJson(payload string, format Format) (string, error) {
+	var decoded map[string]any
+
+	switch format {
+	// kept as is, but a payload declared as JSON must be valid JSON
+	case FormatJSON:
+		err := json.Unmarshal([]byte(payload), &decoded)
+		if err != nil {
+			return "", err
+		}
+
+		return payload, nil
+
+	case FormatYAML:
+		err := yaml.Unmarshal([]byte(payload), &decoded)
+		if err != nil {
+			return "", err
+		}
+
+	case FormatTOML:
+		err := toml.Unmarshal([]byte(payload), &decoded)
+		if err != nil {
+			return "", err
+		}
+
+	default:
+		return "", fmt.Errorf("unknown config format %q", format)
+	}
+
+	payloadJson, err := json.Marshal(decoded)
+	if err != nil {
+		return "", err
+	}
+
+	return string(payloadJson), nil
+}
+
+// normalizePayload converts a payload of any supported format to JSON, sniffing its format
+func normalizePayload(paramPath string, payload string) (string, error) {
+	if payload == "" {
+		return "", nil
+	}
+
+	format := SniffFormat(payload)
+
+	payloadJson, err := toJson(payload, format)
+	if err != nil {
+		return "", fmt.Errorf("error decoding %v as %v: %w", paramPath, format, err)
+	}
+
+	return payloadJson, nil
+}
+
+// DecodePayload decodes a JSON, YAML or TOML payload loaded from paramPath into v
+func DecodePayload(paramPath string, payload string, v any) error {
+	payloadJson, err := normalizePayload(paramPath, payload)
+	if err != nil || payloadJson == "" {
+		return err
+	}
+
+	err = json.Unmarshal([]byte(payloadJson), v)
+	if err != nil {
+		return fmt.Errorf("error decoding %v: %w", paramPath, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSniffFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    Format
+	}{
+		{"json object", `{"port": 8080}`, FormatJSON},
+		{"json array", `[1, 2]`, FormatJSON},
+		{"toml starting with a table", "[main]\ndatabase = \"db\"\nport = 3306", FormatTOML},
+		{"toml assignments", "port = 8080\nlog_level = \"info\"", FormatTOML},
+		{"yaml", "port: 8080\nlog_level: info", FormatYAML},
+		{"yaml value with an equals sign", "dsn: user=app host=db\nport: 8080", FormatYAML},
+		{"yaml flow sequence", "[a, b]", FormatYAML},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SniffFormat(tt.payload); got != tt.want {
+				t.Errorf("SniffFormat() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodePayloadDbPort(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    DbPort
+		wantErr bool
+	}{
+		{"json string", `{"main": {"port": "3306"}}`, "3306", false},
+		{"json number", `{"main": {"port": 3306}}`, "3306", false},
+		{"yaml number", "main:\n  port: 3306", "3306", false},
+		{"yaml string", "main:\n  port: \"3306\"", "3306", false},
+		{"toml number", "[main]\nport = 3306", "3306", false},
+		{"json bool", `{"main": {"port": true}}`, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var db Db
+
+			err := DecodePayload("/test/app/databases", tt.payload, &db)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DecodePayload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := db["main"].Port; got != tt.want {
+				t.Errorf("Port = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalParamSourceDeclaredFormat(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		content  string
+		want     string
+		wantErr  bool
+	}{
+		{"json", ".config.json", `{"port": 4431}`, `{"port": 4431}`, false},
+		{"malformed json is not sniffed as yaml", ".config.json", `{"port": 4431, "log_level": "info",}`, "", true},
+		{"yaml", ".config.yaml", "port: 4431", `{"port":4431}`, false},
+		{"malformed toml", ".config.toml", "port = ", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+
+			err := os.WriteFile(filepath.Join(dir, tt.fileName), []byte(tt.content), 0o644)
+			if err != nil {
+				t.Fatalf("writing %v: %v", tt.fileName, err)
+			}
+
+			source := &LocalParamSource{Dirs: []string{dir}}
+			loadedParams, err := source.Load(context.Background(), ParamPaths{"CONFIG_PATH": "/test/app/config"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got := loadedParams["/test/app/config"]; got != tt.want {
+				t.Errorf("loaded %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Prefix of the environment variables that override config fields, e.g. APP_PORT
+const env_override_prefix = "APP_"
+
+// Names of the layers a config field can come from
+const (
+	layerDefault = "default"
+	layerEnv     = "env"
+	layerOption  = "option"
+)
+
+// Sources keys of the fields set outside the json and env layers
+const (
+	sourceAppName  = "app_name"
+	sourceEnv      = "env"
+	sourceTimezone = "timezone"
+	sourceDb       = "db"
+)
+
+// ConfigSources records which layer set each config field, keyed by the field json name,
+// the registered section name or one of the source keys above
+type ConfigSources map[string]string
+
+// Compiled-in defaults, overridden by the loaded config and then by the environment
+func defaultConfig() Config {
+	return Config{
+		Port:      8080,
+		LogLevel:  "info",
+		LogFormat: "json",
+	}
+}
+
+// applyDefaultsLayer sets the compiled-in defaults and marks every field as coming from them
+func applyDefaultsLayer(config *Config) {
+	*config = defaultConfig()
+	config.Sources = make(ConfigSources)
+
+	for _, name := range layeredFieldNames() {
+		config.Sources[name] = layerDefault
+	}
+}
+
+// applyJsonLayer unmarshals configJson over config, marking each key present in it as
+// coming from layerName
+func applyJsonLayer(config *Config, configJson string, layerName string) error {
+	var keys map[string]json.RawMessage
+
+	if configJson == "" {
+		return nil
+	}
+
+	err := json.Unmarshal([]byte(configJson), &keys)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal([]byte(configJson), config)
+	if err != nil {
+		return err
+	}
+
+	for _, name := range layeredFieldNames() {
+		if _, ok := keys[name]; ok {
+			config.Sources[name] = layerName
+		}
+	}
+
+	return nil
+}
+
+// applyEnvLayer overrides each config field with its APP_<FIELD> environment variable, if set
+func applyEnvLayer(config *Config) error {
+	configValue := reflect.ValueOf(config).Elem()
+	configType := configValue.Type()
+
+	for i := range configType.NumField() {
+		name := jsonFieldName(configType.Field(i))
+		if name == "" {
+			continue
+		}
+
+		envName := env_override_prefix + strings.ToUpper(name)
+		envValue, ok := os.LookupEnv(envName)
+		if !ok {
+			continue
+		}
+
+		err := setFieldFromString(configValue.Field(i), envValue)
+		if err != nil {
+			return fmt.Errorf("invalid value for %v: %w", envName, err)
+		}
+
+		config.Sources[name] = fmt.Sprintf("%v:%v", layerEnv, envName)
+	}
+
+	return nil
+}
+
+// sourceNames lists every key ConfigSources can hold, besides the registered sections
+func sourceNames() []string {
+	return append(layeredFieldNames(), sourceAppName, sourceEnv, sourceTimezone, sourceDb)
+}
+
+// layeredFieldNames lists the json names of the config fields that take part in layering
+func layeredFieldNames() []string {
+	configType := reflect.TypeFor[Config]()
+	names := make([]string, 0, configType.NumField())
+
+	for i := range configType.NumField() {
+		if name := jsonFieldName(configType.Field(i)); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+
+	return name
+}
+
+func setFieldFromString(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(parsed)
+
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+
+	// maps of strings are set from comma separated key=value pairs
+	case reflect.Map:
+		if field.Type().Key().Kind() != reflect.String || field.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported map type %v", field.Type())
+		}
+
+		parsed := reflect.MakeMap(field.Type())
+		for _, pair := range strings.Split(value, ",") {
+			if strings.TrimSpace(pair) == "" {
+				continue
+			}
+
+			key, elem, ok := strings.Cut(pair, "=")
+			if !ok {
+				return fmt.Errorf("invalid key=value pair %q", pair)
+			}
+			parsed.SetMapIndex(reflect.ValueOf(strings.TrimSpace(key)), reflect.ValueOf(strings.TrimSpace(elem)))
+		}
+		field.Set(parsed)
+
+	default:
+		return fmt.Errorf("unsupported field kind %v", field.Kind())
+	}
+
+	return nil
+}
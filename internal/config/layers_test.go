@@ -0,0 +1,87 @@
+package config
+
+import (
+	"testing"
+)
+
+func TestLoadConfigSources(t *testing.T) {
+	t.Setenv("APP_LOG_LEVEL", "debug")
+	t.Setenv("APP_NAME", "app")
+
+	source := &MemoryParamSource{Params: LoadedParams{
+		"/test/app/config":      `{"port": 8081}`,
+		"/test/app/databases":   `{"main": {"database": "db", "host": {"write": "localhost"}, "port": "3306", "username": "user"}}`,
+		"/test/common/timezone": "UTC",
+	}}
+
+	cfg, err := LoadConfig(LoadOptions{Env: "test", Source: source, SkipSecrets: true})
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{"port", "/test/app/config"},
+		{"log_level", "env:APP_LOG_LEVEL"},
+		{"log_format", "default"},
+		{"app_name", "env:APP_NAME"},
+		{"env", "option"},
+		{"timezone", "/test/common/timezone"},
+		{"db", "/test/app/databases"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			if got := cfg.Sources[tt.field]; got != tt.want {
+				t.Errorf("Sources[%q] = %q, want %q", tt.field, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigSkipEnvOverrides(t *testing.T) {
+	t.Setenv("APP_LOG_LEVEL", "debug")
+
+	source := &MemoryParamSource{Params: LoadedParams{
+		"/test/app/config":    `{"port": 8081, "log_level": "warn"}`,
+		"/test/app/databases": `{"main": {"database": "db", "host": {"write": "localhost"}, "port": "3306", "username": "user", "password": "pass"}}`,
+	}}
+
+	tests := []struct {
+		name             string
+		skipEnvOverrides bool
+		wantLevel        string
+	}{
+		{"env overrides applied", false, "debug"},
+		{"env overrides skipped", true, "warn"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadConfig(LoadOptions{
+				Env:              "test",
+				AppName:          "app",
+				Source:           source,
+				SkipSecrets:      true,
+				SkipEnvOverrides: tt.skipEnvOverrides,
+			})
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+
+			if cfg.LogLevel != tt.wantLevel {
+				t.Errorf("LogLevel = %q, want %q", cfg.LogLevel, tt.wantLevel)
+			}
+
+			// both db credentials are secret-tagged
+			db := Dump(cfg)["Db"].(map[string]any)["main"].(map[string]any)
+			for _, field := range []string{"username", "password"} {
+				if db[field] != redacted_value {
+					t.Errorf("Dump() Db.main.%v = %v, want redacted", field, db[field])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,250 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Extensions tried, in order, for the local param files
+var localFileExtensions = []string{".json", ".yaml", ".yml", ".toml"}
+
+// LocalParamSource loads params from local JSON, YAML or TOML files, decoded by their
+// extension and named after the param path key, e.g. CONFIG_PATH is read from .config.json.
+// Each file is searched in Dirs and merged with its environment overlay, e.g.
+// .config.development.json over .config.json. The timezone is read from the TIMEZONE
+// environment variable.
+type LocalParamSource struct {
+	// Config file, skips the search when set
+	ConfigFile string
+
+	// Databases file, skips the search when set
+	DatabasesFile string
+
+	// Directories searched, in order. Defaults to CONFIG_DIR, ./internal/config, the
+	// working directory, the executable directory and the user config directory
+	// ($XDG_CONFIG_HOME/{app}).
+	Dirs []string
+
+	// Environment of the overlay files, no overlays are loaded if empty
+	Env string
+
+	// Application name, used to build the user config directory
+	AppName string
+}
+
+func (l *LocalParamSource) Load(_ context.Context, paramPaths ParamPaths) (loadedParams LoadedParams, err error) {
+	loadedParams = make(LoadedParams, len(paramPaths))
+
+	for key, path := range paramPaths {
+		if key == "TIMEZONE_PATH" {
+			loadedParams[path] = os.Getenv("TIMEZONE")
+			continue
+		}
+
+		loadedParams[path], err = l.readFile(l.fileFor(key), localBaseName(key))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return loadedParams, nil
+}
+
+// fileFor returns the file explicitly set for a param path key, if any
+func (l *LocalParamSource) fileFor(key string) string {
+	switch key {
+	case "CONFIG_PATH":
+		return l.ConfigFile
+	case "DATABASES_PATH":
+		return l.DatabasesFile
+	default:
+		return ""
+	}
+}
+
+// SearchDirs returns the directories searched for the param files
+func (l *LocalParamSource) SearchDirs() []string {
+	if l.Dirs != nil {
+		return l.Dirs
+	}
+
+	dirs := make([]string, 0, 5)
+
+	if configDir := os.Getenv("CONFIG_DIR"); configDir != "" {
+		dirs = append(dirs, configDir)
+	}
+
+	dirs = append(dirs, "./internal/config", ".")
+
+	if executable, err := os.Executable(); err == nil {
+		dirs = append(dirs, filepath.Dir(executable))
+	}
+
+	if userConfigDir, err := os.UserConfigDir(); err == nil && l.AppName != "" {
+		dirs = append(dirs, filepath.Join(userConfigDir, l.AppName))
+	}
+
+	return dirs
+}
+
+// readFile reads file, or the first file named baseName found in the search dirs, merges
+// its environment overlay over it and returns the result as JSON
+func (l *LocalParamSource) readFile(file string, baseName string) (string, error) {
+	var err error
+
+	if file == "" {
+		file, err = l.findFile(baseName)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	contentJson, err := readLocalFileAsJson(file)
+	if err != nil {
+		return "", err
+	}
+
+	if l.Env == "" {
+		return contentJson, nil
+	}
+
+	fileBase := strings.TrimSuffix(file, filepath.Ext(file))
+	overlayFile, err := findFileWithExtension(fileBase + "." + l.Env)
+	if errors.Is(err, fs.ErrNotExist) {
+		return contentJson, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	overlayJson, err := readLocalFileAsJson(overlayFile)
+	if err != nil {
+		return "", err
+	}
+
+	return mergeJson(contentJson, overlayJson)
+}
+
+// findFile looks for baseName with every supported extension in every search dir, listing
+// every location tried if none is found
+func (l *LocalParamSource) findFile(baseName string) (string, error) {
+	tried := make([]string, 0)
+
+	for _, dir := range l.SearchDirs() {
+		file, err := findFileWithExtension(filepath.Join(dir, baseName))
+		if err == nil {
+			return file, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+
+		for _, extension := range localFileExtensions {
+			tried = append(tried, filepath.Join(dir, baseName+extension))
+		}
+	}
+
+	return "", &localFileNotFoundError{baseName, tried}
+}
+
+// localFileNotFoundError lists every location tried for a missing param file
+type localFileNotFoundError struct {
+	baseName string
+	tried    []string
+}
+
+func (l *localFileNotFoundError) Error() string {
+	return fmt.Sprintf("no %v file found, tried:\n  %v", l.baseName, strings.Join(l.tried, "\n  "))
+}
+
+func (l *localFileNotFoundError) Is(target error) bool {
+	return target == ErrParamNotFound
+}
+
+// localBaseName returns the file base name of a param path key, e.g. .config for CONFIG_PATH
+func localBaseName(key string) string {
+	return "." + strings.ToLower(strings.TrimSuffix(key, "_PATH"))
+}
+
+// findFileWithExtension returns the first existing fileBase + extension, or fs.ErrNotExist
+func findFileWithExtension(fileBase string) (string, error) {
+	for _, extension := range localFileExtensions {
+		file := fileBase + extension
+
+		_, err := os.Stat(file)
+		if err == nil {
+			return file, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", err
+		}
+	}
+
+	return "", fs.ErrNotExist
+}
+
+func readLocalFileAsJson(file string) (string, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+
+	format, ok := FormatFromExtension(file)
+	if !ok {
+		format = SniffFormat(string(content))
+	}
+
+	contentJson, err := toJson(string(content), format)
+	if err != nil {
+		return "", fmt.Errorf("error decoding %v as %v: %w", file, format, err)
+	}
+
+	return contentJson, nil
+}
+
+// mergeJson deep merges the overlay JSON object over the base one
+func mergeJson(baseJson string, overlayJson string) (string, error) {
+	var base, overlay map[string]any
+
+	err := json.Unmarshal([]byte(baseJson), &base)
+	if err != nil {
+		return "", err
+	}
+
+	err = json.Unmarshal([]byte(overlayJson), &overlay)
+	if err != nil {
+		return "", err
+	}
+
+	merged, err := json.Marshal(mergeMaps(base, overlay))
+	if err != nil {
+		return "", err
+	}
+
+	return string(merged), nil
+}
+
+func mergeMaps(base map[string]any, overlay map[string]any) map[string]any {
+	if base == nil {
+		base = make(map[string]any, len(overlay))
+	}
+
+	for key, overlayValue := range overlay {
+		baseMap, baseIsMap := base[key].(map[string]any)
+		overlayMap, overlayIsMap := overlayValue.(map[string]any)
+
+		if baseIsMap && overlayIsMap {
+			base[key] = mergeMaps(baseMap, overlayMap)
+		} else {
+			base[key] = overlayValue
+		}
+	}
+
+	return base
+}
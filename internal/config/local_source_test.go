@@ -0,0 +1,192 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes files, keyed by path, creating their directories
+func writeFiles(t *testing.T, files map[string]string) {
+	t.Helper()
+
+	for file, content := range files {
+		err := os.MkdirAll(filepath.Dir(file), 0o755)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		err = os.WriteFile(file, []byte(content), 0o644)
+		if err != nil {
+			t.Fatalf("writing %v: %v", file, err)
+		}
+	}
+}
+
+func TestLocalParamSourceSearchOrder(t *testing.T) {
+	root := t.TempDir()
+	first := filepath.Join(root, "first")
+	second := filepath.Join(root, "second")
+	explicit := filepath.Join(root, "explicit.yaml")
+
+	tests := []struct {
+		name   string
+		files  map[string]string
+		source LocalParamSource
+		want   string
+	}{
+		{
+			name: "first dir wins",
+			files: map[string]string{
+				filepath.Join(first, ".config.json"):  `{"port":1}`,
+				filepath.Join(second, ".config.json"): `{"port":2}`,
+			},
+			want: `{"port":1}`,
+		},
+		{
+			name:  "later dir",
+			files: map[string]string{filepath.Join(second, ".config.json"): `{"port":2}`},
+			want:  `{"port":2}`,
+		},
+		{
+			name: "extension order within a dir",
+			files: map[string]string{
+				filepath.Join(first, ".config.yaml"): "port: 3",
+				filepath.Join(first, ".config.json"): `{"port":1}`,
+			},
+			want: `{"port":1}`,
+		},
+		{
+			name: "extension order before dir order",
+			files: map[string]string{
+				filepath.Join(first, ".config.toml"):  "port = 4",
+				filepath.Join(second, ".config.json"): `{"port":2}`,
+			},
+			want: `{"port":4}`,
+		},
+		{
+			name: "explicit file skips the search",
+			files: map[string]string{
+				filepath.Join(first, ".config.json"): `{"port":1}`,
+				explicit:                             "port: 5",
+			},
+			source: LocalParamSource{ConfigFile: explicit},
+			want:   `{"port":5}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, dir := range []string{first, second} {
+				os.RemoveAll(dir)
+			}
+			os.Remove(explicit)
+			writeFiles(t, tt.files)
+
+			source := tt.source
+			source.Dirs = []string{first, second}
+
+			loadedParams, err := source.Load(context.Background(), ParamPaths{"CONFIG_PATH": "/test/app/config"})
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			if got := loadedParams["/test/app/config"]; got != tt.want {
+				t.Errorf("loaded %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalParamSourceOverlay(t *testing.T) {
+	dir := t.TempDir()
+	otherDir := t.TempDir()
+
+	writeFiles(t, map[string]string{
+		filepath.Join(dir, ".config.json"):              `{"port": 4431, "log_sampling": {"default": {"first": 10}, "levels": {"info": {"first": 5}}}}`,
+		filepath.Join(dir, ".config.development.yaml"):  "log_sampling:\n  levels:\n    debug:\n      first: 1\n",
+		filepath.Join(dir, ".config.production.json"):   `{"port": 443, "log_sampling": {"default": null}}`,
+		filepath.Join(otherDir, ".config.staging.json"): `{"port": 8443}`,
+	})
+
+	tests := []struct {
+		name string
+		env  string
+		want string
+	}{
+		{"no env", "", `{"port": 4431, "log_sampling": {"default": {"first": 10}, "levels": {"info": {"first": 5}}}}`},
+		{"nested maps are merged", "development", `{"log_sampling":{"default":{"first":10},"levels":{"debug":{"first":1},"info":{"first":5}}},"port":4431}`},
+		{"overlay values replace the base ones", "production", `{"log_sampling":{"default":null,"levels":{"info":{"first":5}}},"port":443}`},
+		{"no overlay", "test", `{"port": 4431, "log_sampling": {"default": {"first": 10}, "levels": {"info": {"first": 5}}}}`},
+		{"overlays are only read next to the base file", "staging", `{"port": 4431, "log_sampling": {"default": {"first": 10}, "levels": {"info": {"first": 5}}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &LocalParamSource{Dirs: []string{dir, otherDir}, Env: tt.env}
+
+			loadedParams, err := source.Load(context.Background(), ParamPaths{"CONFIG_PATH": "/test/app/config"})
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			if got := loadedParams["/test/app/config"]; got != tt.want {
+				t.Errorf("loaded %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalParamSourceNotFound(t *testing.T) {
+	first := t.TempDir()
+	second := t.TempDir()
+
+	source := &LocalParamSource{Dirs: []string{first, second}}
+	_, err := source.Load(context.Background(), ParamPaths{"DATABASES_PATH": "/test/app/databases"})
+	if err == nil {
+		t.Fatalf("Load() found a databases file in empty dirs")
+	}
+
+	if !errors.Is(err, ErrParamNotFound) {
+		t.Errorf("Load() error = %v, want it to match ErrParamNotFound", err)
+	}
+
+	wantLines := []string{"no .databases file found, tried:"}
+	for _, dir := range []string{first, second} {
+		for _, extension := range localFileExtensions {
+			wantLines = append(wantLines, "  "+filepath.Join(dir, ".databases"+extension))
+		}
+	}
+
+	if got := strings.Split(err.Error(), "\n"); !slices.Equal(got, wantLines) {
+		t.Errorf("Load() error lines = %q, want %q", got, wantLines)
+	}
+}
+
+func TestLocalParamSourceSearchDirs(t *testing.T) {
+	configDir := t.TempDir()
+	userConfigDir := t.TempDir()
+
+	t.Setenv("CONFIG_DIR", configDir)
+	t.Setenv("XDG_CONFIG_HOME", userConfigDir)
+
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dirs := (&LocalParamSource{AppName: "app"}).SearchDirs()
+	want := []string{configDir, "./internal/config", ".", filepath.Dir(executable), filepath.Join(userConfigDir, "app")}
+	if !slices.Equal(dirs, want) {
+		t.Errorf("SearchDirs() = %v, want %v", dirs, want)
+	}
+
+	explicitDirs := []string{"a", "b"}
+	if dirs := (&LocalParamSource{Dirs: explicitDirs, AppName: "app"}).SearchDirs(); !slices.Equal(dirs, explicitDirs) {
+		t.Errorf("SearchDirs() = %v, want the explicit %v", dirs, explicitDirs)
+	}
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+)
+
+// Source used when CONFIG_SOURCE is not set, keyed by GO_ENV
+var defaultParamSources = map[string]string{
+	"production":  "ssm",
+	"development": "local",
+	"testing":     "memory",
+}
+
+// ErrParamNotFound is matched, with errors.Is, by the errors of the sources missing a
+// requested param
+var ErrParamNotFound = errors.New("config param not found")
+
+// ParamSource loads the raw config params referenced by a set of param paths
+type ParamSource interface {
+	Load(ctx context.Context, paramPaths ParamPaths) (LoadedParams, error)
+}
+
+// NewParamSource builds the param source registered under name. If name is empty,
+// the default source for env is used.
+func NewParamSource(name string, env string) (ParamSource, error) {
+	if name == "" {
+		name = defaultParamSources[env]
+	}
+
+	switch name {
+	case "ssm":
+		return &SSMParamSource{}, nil
+	case "ssm-path":
+		return &SSMParamSource{ByPath: true}, nil
+	case "local":
+		return &LocalParamSource{}, nil
+	case "env":
+		return &EnvParamSource{}, nil
+	case "memory":
+		return &MemoryParamSource{}, nil
+	default:
+		return nil, fmt.Errorf("config source must be one of [ssm, ssm-path, local, env, memory], got %q", name)
+	}
+}
+
+// EnvParamSource reads each param from an environment variable named after its
+// param path key, e.g. CONFIG_PATH is read from PARAM_CONFIG
+type EnvParamSource struct {
+	// Environment variable prefix, defaults to PARAM_
+	Prefix string
+}
+
+func (e *EnvParamSource) Load(_ context.Context, paramPaths ParamPaths) (LoadedParams, error) {
+	prefix := e.Prefix
+	if prefix == "" {
+		prefix = "PARAM_"
+	}
+
+	loadedParams := make(LoadedParams, len(paramPaths))
+	for key, path := range paramPaths {
+		envName := prefix + strings.TrimSuffix(key, "_PATH")
+		if value, ok := os.LookupEnv(envName); ok {
+			loadedParams[path] = value
+		}
+	}
+
+	return loadedParams, nil
+}
+
+// MemoryParamSource serves params from a map, keyed by param path. Useful for tests.
+type MemoryParamSource struct {
+	Params LoadedParams
+}
+
+func (m *MemoryParamSource) Load(_ context.Context, _ ParamPaths) (LoadedParams, error) {
+	loadedParams := make(LoadedParams, len(m.Params))
+	maps.Copy(loadedParams, m.Params)
+
+	return loadedParams, nil
+}
@@ -0,0 +1,272 @@
+package config
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+// Default local encrypted secrets file, relative to the working directory
+const default_secrets_file = "./internal/config/.secrets.enc"
+
+// Interface to allow mocking of the AWS Secrets Manager API
+type SecretsManagerClient interface {
+	GetSecretValue(ctx context.Context, input *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+// SecretsManagerProvider fetches secrets from AWS Secrets Manager
+type SecretsManagerProvider struct {
+	// Client used to call the Secrets Manager API. Built from AWSConfig if nil.
+	Client SecretsManagerClient
+
+	// AWS config used to build the client, defaults to the shared AWS config
+	AWSConfig *aws.Config
+
+	// Custom Secrets Manager API endpoint, e.g. a LocalStack URL
+	Endpoint string
+
+	// guards the lazy build of Client, secrets are fetched concurrently
+	clientMu sync.Mutex
+}
+
+func (s *SecretsManagerProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	client, err := s.client(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(name),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if output.SecretString == nil {
+		return "", fmt.Errorf("secret %q has no string value", name)
+	}
+
+	return *output.SecretString, nil
+}
+
+func (s *SecretsManagerProvider) client(ctx context.Context) (SecretsManagerClient, error) {
+	s.clientMu.Lock()
+	defer s.clientMu.Unlock()
+
+	if s.Client != nil {
+		return s.Client, nil
+	}
+
+	cfg, err := awsConfigOrDefault(ctx, s.AWSConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Client = secretsmanager.NewFromConfig(cfg, func(o *secretsmanager.Options) {
+		if s.Endpoint != "" {
+			o.BaseEndpoint = aws.String(s.Endpoint)
+		}
+	})
+
+	return s.Client, nil
+}
+
+// SSMSecretProvider fetches secrets from SSM SecureString params
+type SSMSecretProvider struct {
+	// Client used to call the SSM API. Built from AWSConfig if nil.
+	Client SSMClient
+
+	// AWS config used to build the client, defaults to the shared AWS config
+	AWSConfig *aws.Config
+
+	// Custom SSM API endpoint, e.g. a LocalStack URL
+	Endpoint string
+
+	// guards the lazy build of Client, secrets are fetched concurrently
+	clientMu sync.Mutex
+}
+
+func (s *SSMSecretProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	client, err := s.client(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	if !strings.HasPrefix(name, "/") {
+		name = "/" + name
+	}
+
+	output, err := client.GetParameters(ctx, &ssm.GetParametersInput{
+		Names:          []string{name},
+		WithDecryption: aws.Bool(true),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(output.Parameters) == 0 {
+		return "", &MissingParamsError{Names: []string{name}}
+	}
+
+	return *output.Parameters[0].Value, nil
+}
+
+func (s *SSMSecretProvider) client(ctx context.Context) (SSMClient, error) {
+	s.clientMu.Lock()
+	defer s.clientMu.Unlock()
+
+	if s.Client != nil {
+		return s.Client, nil
+	}
+
+	cfg, err := awsConfigOrDefault(ctx, s.AWSConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Client = ssm.NewFromConfig(cfg, func(o *ssm.Options) {
+		if s.Endpoint != "" {
+			o.BaseEndpoint = aws.String(s.Endpoint)
+		}
+	})
+
+	return s.Client, nil
+}
+
+// FileSecretProvider reads secrets from a local AES-256-GCM encrypted JSON object file,
+// mapping secret names to values. Meant for development.
+type FileSecretProvider struct {
+	// Encrypted secrets file, defaults to ./internal/config/.secrets.enc
+	File string
+
+	// Base64 encoded 32 byte key, defaults to SECRETS_KEY
+	Key string
+}
+
+func (f *FileSecretProvider) GetSecret(_ context.Context, name string) (string, error) {
+	var secrets map[string]any
+
+	file := f.File
+	if file == "" {
+		file = default_secrets_file
+	}
+
+	key, err := f.key()
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := OpenSecrets(key, sealed)
+	if err != nil {
+		return "", err
+	}
+
+	err = json.Unmarshal(plaintext, &secrets)
+	if err != nil {
+		return "", err
+	}
+
+	value, ok := secrets[name]
+	if !ok {
+		return "", fmt.Errorf("secret %q not found in %v", name, file)
+	}
+
+	// object secrets are returned as JSON so keys can be selected with #
+	if _, isObject := value.(map[string]any); isObject {
+		valueJson, err := json.Marshal(value)
+		return string(valueJson), err
+	}
+
+	return fmt.Sprint(value), nil
+}
+
+func (f *FileSecretProvider) key() ([]byte, error) {
+	encodedKey := f.Key
+	if encodedKey == "" {
+		encodedKey = os.Getenv("SECRETS_KEY")
+	}
+
+	if encodedKey == "" {
+		return nil, errors.New("no key to decrypt the secrets file, set SECRETS_KEY")
+	}
+
+	return base64.StdEncoding.DecodeString(encodedKey)
+}
+
+// SealSecrets encrypts plaintext with AES-256-GCM, in the format read by FileSecretProvider
+func SealSecrets(key []byte, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+
+	return []byte(base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// OpenSecrets decrypts a file sealed by SealSecrets
+func OpenSecrets(key []byte, sealed []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(sealed)))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(decoded) < gcm.NonceSize() {
+		return nil, errors.New("secrets file is too short")
+	}
+
+	nonce, ciphertext := decoded[:gcm.NonceSize()], decoded[gcm.NonceSize():]
+
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("secrets key must be 32 bytes, got %v", len(key))
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func awsConfigOrDefault(ctx context.Context, awsConfig *aws.Config) (aws.Config, error) {
+	if awsConfig != nil {
+		return *awsConfig, nil
+	}
+
+	return config.LoadDefaultConfig(ctx)
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// newFakeSecretsManager serves GetSecretValue, answering each secret id with "value of <id>"
+func newFakeSecretsManager(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var input struct{ SecretId string }
+
+		err := json.NewDecoder(r.Body).Decode(&input)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		json.NewEncoder(w).Encode(map[string]string{
+			"Name":         input.SecretId,
+			"SecretString": "value of " + input.SecretId,
+		})
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func TestSecretsManagerProviderConcurrentClientBuild(t *testing.T) {
+	server := newFakeSecretsManager(t)
+
+	awsConfig := aws.Config{
+		Region: "us-east-1",
+		Credentials: aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "test", SecretAccessKey: "test"}, nil
+		}),
+	}
+
+	// the client is built by the first fetches, which run concurrently
+	resolver := newTestResolver(&SecretsManagerProvider{AWSConfig: &awsConfig, Endpoint: server.URL})
+
+	var wg sync.WaitGroup
+	results := make([]string, 4)
+	errs := make([]error, 4)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = resolver.Resolve(context.Background(), fmt.Sprintf("secret://name%v", i))
+		}()
+	}
+	wg.Wait()
+
+	for i, result := range results {
+		if errs[i] != nil {
+			t.Fatalf("Resolve(name%v) error = %v", i, errs[i])
+		}
+		if want := fmt.Sprintf("value of name%v", i); result != want {
+			t.Errorf("Resolve(name%v) = %q, want %q", i, result, want)
+		}
+	}
+}
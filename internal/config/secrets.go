@@ -0,0 +1,261 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default time a resolved secret is cached before being fetched again, so rotations are
+// picked up by the next config reload
+const default_secret_cache_ms_ttl = 5 * 60 * 1000
+
+// Provider used by secret:// references when SECRETS_PROVIDER is not set, keyed by GO_ENV
+var defaultSecretProviders = map[string]string{
+	"production":  "secretsmanager",
+	"development": "file",
+}
+
+// SecretProvider fetches the raw value of a secret by name
+type SecretProvider interface {
+	GetSecret(ctx context.Context, name string) (string, error)
+}
+
+type SecretResolverOptions struct {
+	// Providers keyed by name, referenced as secret+<name>://. Defaults to the
+	// secretsmanager, ssm and file providers.
+	Providers map[string]SecretProvider
+
+	// Provider used by plain secret:// references, defaults to SECRETS_PROVIDER or the
+	// default provider for GO_ENV
+	DefaultProvider string
+
+	// Time a resolved secret is cached
+	CacheTTL *int
+}
+
+type cachedSecret struct {
+	value     string
+	fetchedAt time.Time
+}
+
+// secretFetch is an ongoing provider call, shared by the concurrent resolutions of a secret
+type secretFetch struct {
+	done  chan struct{}
+	value string
+	err   error
+}
+
+// SecretResolver resolves secret references such as "secret://prod/main-db#password"
+// or "secret+ssm://prod/main-db/password", caching the fetched values
+type SecretResolver struct {
+	options  SecretResolverOptions
+	cache    map[string]cachedSecret
+	fetching map[string]*secretFetch
+	mu       sync.Mutex
+}
+
+func NewSecretResolver(opts SecretResolverOptions) *SecretResolver {
+	if opts.Providers == nil {
+		opts.Providers = map[string]SecretProvider{
+			"secretsmanager": &SecretsManagerProvider{},
+			"ssm":            &SSMSecretProvider{},
+			"file":           &FileSecretProvider{},
+		}
+	}
+
+	if opts.DefaultProvider == "" {
+		opts.DefaultProvider = os.Getenv("SECRETS_PROVIDER")
+	}
+
+	if opts.DefaultProvider == "" {
+		opts.DefaultProvider = defaultSecretProviders[os.Getenv("GO_ENV")]
+	}
+
+	if opts.CacheTTL == nil {
+		defaultCacheTTL := default_secret_cache_ms_ttl
+		opts.CacheTTL = &defaultCacheTTL
+	}
+
+	return &SecretResolver{
+		options:  opts,
+		cache:    make(map[string]cachedSecret),
+		fetching: make(map[string]*secretFetch),
+	}
+}
+
+// IsSecretRef reports whether value is a secret reference
+func IsSecretRef(value string) bool {
+	return strings.HasPrefix(value, "secret://") || strings.HasPrefix(value, "secret+")
+}
+
+// Resolve returns the secret value referenced by value. Values that aren't secret references
+// are returned as is. The part after # selects a key of a JSON object secret.
+func (s *SecretResolver) Resolve(ctx context.Context, value string) (string, error) {
+	if !IsSecretRef(value) {
+		return value, nil
+	}
+
+	providerName, name, key, err := s.parseRef(value)
+	if err != nil {
+		return "", err
+	}
+
+	provider, ok := s.options.Providers[providerName]
+	if !ok {
+		return "", fmt.Errorf("unknown secret provider %q in %q", providerName, value)
+	}
+
+	rawSecret, err := s.fetch(ctx, providerName, provider, name)
+	if err != nil {
+		return "", fmt.Errorf("error resolving %q: %w", value, err)
+	}
+
+	if key == "" {
+		return rawSecret, nil
+	}
+
+	var secretFields map[string]any
+	err = json.Unmarshal([]byte(rawSecret), &secretFields)
+	if err != nil {
+		return "", fmt.Errorf("secret %q is not a JSON object, can't select key %q", name, key)
+	}
+
+	field, ok := secretFields[key]
+	if !ok {
+		return "", fmt.Errorf("secret %q has no key %q", name, key)
+	}
+
+	return fmt.Sprint(field), nil
+}
+
+// resolveSecrets replaces the secret references in every string field tagged
+// `secret:"true"` reachable from v, which must be a pointer
+func (s *SecretResolver) resolveSecrets(ctx context.Context, v any) error {
+	return s.resolveValue(ctx, reflect.ValueOf(v), false)
+}
+
+func (s *SecretResolver) resolveValue(ctx context.Context, value reflect.Value, secret bool) error {
+	switch value.Kind() {
+	case reflect.Pointer:
+		if !value.IsNil() {
+			return s.resolveValue(ctx, value.Elem(), secret)
+		}
+
+	case reflect.Struct:
+		valueType := value.Type()
+		for i := range valueType.NumField() {
+			field := valueType.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			err := s.resolveValue(ctx, value.Field(i), field.Tag.Get("secret") == "true")
+			if err != nil {
+				return err
+			}
+		}
+
+	// map elements aren't addressable, they are resolved in a copy
+	case reflect.Map:
+		for _, key := range value.MapKeys() {
+			elem := reflect.New(value.Type().Elem()).Elem()
+			elem.Set(value.MapIndex(key))
+
+			err := s.resolveValue(ctx, elem, secret)
+			if err != nil {
+				return err
+			}
+
+			value.SetMapIndex(key, elem)
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := range value.Len() {
+			err := s.resolveValue(ctx, value.Index(i), secret)
+			if err != nil {
+				return err
+			}
+		}
+
+	case reflect.String:
+		if !secret || !value.CanSet() {
+			return nil
+		}
+
+		resolved, err := s.Resolve(ctx, value.String())
+		if err != nil {
+			return err
+		}
+		value.SetString(resolved)
+	}
+
+	return nil
+}
+
+func (s *SecretResolver) parseRef(ref string) (providerName string, name string, key string, err error) {
+	scheme, rest, _ := strings.Cut(ref, "://")
+
+	providerName = s.options.DefaultProvider
+	if explicitProvider, ok := strings.CutPrefix(scheme, "secret+"); ok {
+		providerName = explicitProvider
+	}
+
+	if providerName == "" {
+		return "", "", "", fmt.Errorf("no secret provider configured to resolve %q, set SECRETS_PROVIDER", ref)
+	}
+
+	name, key, _ = strings.Cut(rest, "#")
+	if name == "" {
+		return "", "", "", fmt.Errorf("invalid secret reference %q", ref)
+	}
+
+	return providerName, name, key, nil
+}
+
+// fetch returns the cached secret or gets it from provider. The lock is not held during
+// the provider call, concurrent fetches of the same secret wait for a single call.
+func (s *SecretResolver) fetch(ctx context.Context, providerName string, provider SecretProvider, name string) (string, error) {
+	cacheKey := providerName + "://" + name
+	ttl := time.Millisecond * time.Duration(*s.options.CacheTTL)
+
+	s.mu.Lock()
+
+	if cached, ok := s.cache[cacheKey]; ok && time.Since(cached.fetchedAt) < ttl {
+		s.mu.Unlock()
+		return cached.value, nil
+	}
+
+	if ongoing, ok := s.fetching[cacheKey]; ok {
+		s.mu.Unlock()
+
+		select {
+		case <-ongoing.done:
+			return ongoing.value, ongoing.err
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+
+	ongoing := &secretFetch{done: make(chan struct{})}
+	s.fetching[cacheKey] = ongoing
+	s.mu.Unlock()
+
+	ongoing.value, ongoing.err = provider.GetSecret(ctx, name)
+
+	s.mu.Lock()
+	delete(s.fetching, cacheKey)
+	if ongoing.err == nil {
+		s.cache[cacheKey] = cachedSecret{value: ongoing.value, fetchedAt: time.Now()}
+	}
+	s.mu.Unlock()
+
+	close(ongoing.done)
+
+	return ongoing.value, ongoing.err
+}
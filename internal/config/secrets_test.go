@@ -0,0 +1,179 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSecretProvider serves secrets from a map, blocking the names in block until released
+type fakeSecretProvider struct {
+	secrets map[string]string
+	block   map[string]chan struct{}
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (f *fakeSecretProvider) callCount(name string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls[name]
+}
+
+func (f *fakeSecretProvider) GetSecret(ctx context.Context, name string) (string, error) {
+	f.mu.Lock()
+	if f.calls == nil {
+		f.calls = map[string]int{}
+	}
+	f.calls[name]++
+	f.mu.Unlock()
+
+	if release, ok := f.block[name]; ok {
+		<-release
+	}
+
+	secret, ok := f.secrets[name]
+	if !ok {
+		return "", fmt.Errorf("secret %q not found", name)
+	}
+
+	return secret, nil
+}
+
+func newTestResolver(provider SecretProvider) *SecretResolver {
+	return NewSecretResolver(SecretResolverOptions{
+		Providers:       map[string]SecretProvider{"fake": provider},
+		DefaultProvider: "fake",
+	})
+}
+
+func TestSecretResolverResolve(t *testing.T) {
+	resolver := newTestResolver(&fakeSecretProvider{secrets: map[string]string{
+		"db":    `{"username": "admin", "password": "hunter2"}`,
+		"token": "s3cret",
+	}})
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{"plain value", "plain value", false},
+		{"secret://token", "s3cret", false},
+		{"secret+fake://db#password", "hunter2", false},
+		{"secret://db#missing", "", true},
+		{"secret://token#key", "", true},
+		{"secret://unknown", "", true},
+		{"secret+other://token", "", true},
+		{"secret://", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got, err := resolver.Resolve(context.Background(), tt.ref)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSecretResolverFetchConcurrency(t *testing.T) {
+	release := make(chan struct{})
+	provider := &fakeSecretProvider{
+		secrets: map[string]string{"slow": "slow value", "fast": "fast value"},
+		block:   map[string]chan struct{}{"slow": release},
+	}
+	resolver := newTestResolver(provider)
+
+	var wg sync.WaitGroup
+	results := make([]string, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], _ = resolver.Resolve(context.Background(), "secret://slow")
+		}()
+	}
+
+	// a slow secret doesn't block the others
+	fastDone := make(chan string)
+	go func() {
+		value, _ := resolver.Resolve(context.Background(), "secret://fast")
+		fastDone <- value
+	}()
+
+	select {
+	case value := <-fastDone:
+		if value != "fast value" {
+			t.Errorf("Resolve(fast) = %q", value)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Resolve(fast) blocked by an ongoing fetch of another secret")
+	}
+
+	// lets the resolutions of slow join the ongoing fetch
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for i, result := range results {
+		if result != "slow value" {
+			t.Errorf("results[%v] = %q, want %q", i, result, "slow value")
+		}
+	}
+
+	if calls := provider.callCount("slow"); calls != 1 {
+		t.Errorf("slow fetched %v times, want 1", calls)
+	}
+
+	resolver.Resolve(context.Background(), "secret://slow")
+	if calls := provider.callCount("slow"); calls != 1 {
+		t.Errorf("cached secret fetched again")
+	}
+}
+
+func TestResolveSecretTaggedFields(t *testing.T) {
+	resolver := newTestResolver(&fakeSecretProvider{secrets: map[string]string{
+		"db":    `{"username": "admin", "password": "hunter2"}`,
+		"token": "s3cret",
+	}})
+
+	cfg := validConfig()
+	cfg.AdminToken = "secret://token"
+	cfg.LogLevel = "secret://token"
+
+	conn := validDbConnConfig()
+	conn.Username = "secret://db#username"
+	conn.Password = "secret://db#password"
+	cfg.Db["main"] = conn
+
+	err := resolver.resolveSecrets(context.Background(), &cfg)
+	if err != nil {
+		t.Fatalf("resolveSecrets() error = %v", err)
+	}
+
+	tests := []struct {
+		field string
+		got   string
+		want  string
+	}{
+		{"AdminToken", cfg.AdminToken, "s3cret"},
+		{"Db.main.username", cfg.Db["main"].Username, "admin"},
+		{"Db.main.password", cfg.Db["main"].Password, "hunter2"},
+		{"untagged log_level", cfg.LogLevel, "secret://token"},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%v = %q, want %q", tt.field, tt.got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// Sections registered by the application modules, keyed by their config json key
+var (
+	registeredSections   = make(map[string]sectionDecoder)
+	registeredSectionsMu sync.RWMutex
+)
+
+// sectionDecoder decodes the raw json of a section into its registered type, resolving its
+// secret references with resolveSecrets unless nil
+type sectionDecoder func(raw json.RawMessage, resolveSecrets func(v any) error) (any, error)
+
+// Section is a typed part of the config owned by an application module, decoded from
+// the config json key it was registered with
+type Section[T any] struct {
+	name string
+}
+
+// Register declares a config section decoded into T from the name key of the config json,
+// e.g. config.Register[PaymentsConfig]("payments"). T is validated by its `validate` tags
+// and the secret references in its fields tagged `secret:"true"` are resolved.
+// Meant to be called at package initialization; panics on duplicated or reserved names.
+func Register[T any](name string) *Section[T] {
+	registeredSectionsMu.Lock()
+	defer registeredSectionsMu.Unlock()
+
+	if _, exists := registeredSections[name]; exists {
+		panic(fmt.Sprintf("config section %q registered twice", name))
+	}
+
+	if slices.Contains(sourceNames(), name) {
+		panic(fmt.Sprintf("config section %q clashes with a core config field", name))
+	}
+
+	registeredSections[name] = func(raw json.RawMessage, resolveSecrets func(v any) error) (any, error) {
+		var section T
+
+		if raw != nil {
+			err := json.Unmarshal(raw, &section)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if resolveSecrets != nil {
+			err := resolveSecrets(&section)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return section, nil
+	}
+
+	return &Section[T]{name}
+}
+
+func (s *Section[T]) Name() string {
+	return s.name
+}
+
+// Get returns the section value in the config snapshot c
+func (s *Section[T]) Get(c *Config) T {
+	section, _ := c.sections[s.name].(T)
+	return section
+}
+
+// decodeSections decodes and validates every registered section from configJson, adding
+// the violations found to errs and the layer of each section to sources
+func decodeSections(configJson string, layerName string, sources ConfigSources, resolveSecrets func(v any) error, errs *ValidationErrors) (map[string]any, error) {
+	var keys map[string]json.RawMessage
+
+	if configJson != "" {
+		err := json.Unmarshal([]byte(configJson), &keys)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	registeredSectionsMu.RLock()
+	defer registeredSectionsMu.RUnlock()
+
+	sections := make(map[string]any, len(registeredSections))
+	for name, decode := range registeredSections {
+		section, err := decode(keys[name], resolveSecrets)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding config section %q: %w", name, err)
+		}
+
+		validateAt(section, name, errs)
+		sections[name] = section
+
+		sources[name] = layerDefault
+		if _, ok := keys[name]; ok {
+			sources[name] = layerName
+		}
+	}
+
+	return sections, nil
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+type testPaymentsConfig struct {
+	Provider string `json:"provider"`
+	Retries  *int   `json:"retries" validate:"min=1,max=5"`
+	ApiKey   string `json:"api_key" secret:"true"`
+}
+
+// registered once for the whole package, every rule passes on the zero value so the other
+// tests loading a config are not affected
+var testPaymentsSection = Register[testPaymentsConfig]("test_payments")
+
+func TestSectionLoad(t *testing.T) {
+	tests := []struct {
+		name        string
+		configJson  string
+		skipSecrets bool
+		want        testPaymentsConfig
+		wantRetries int
+		wantSource  string
+		wantErrs    []string
+	}{
+		{
+			name:        "decoded with its secrets resolved",
+			configJson:  `{"test_payments": {"provider": "stripe", "retries": 3, "api_key": "secret://payments"}}`,
+			want:        testPaymentsConfig{Provider: "stripe", ApiKey: "sk_live"},
+			wantRetries: 3,
+			wantSource:  "/test/app/config",
+		},
+		{
+			name:        "secret references left when skipping secrets",
+			configJson:  `{"test_payments": {"api_key": "secret://payments"}}`,
+			skipSecrets: true,
+			want:        testPaymentsConfig{ApiKey: "secret://payments"},
+			wantSource:  "/test/app/config",
+		},
+		{
+			name:       "missing section",
+			configJson: `{"port": 4431}`,
+			wantSource: layerDefault,
+		},
+		{
+			name:       "validation paths prefixed with the section name",
+			configJson: `{"port": 0, "test_payments": {"retries": 9}}`,
+			wantErrs:   []string{"port: must be at least 1", "test_payments.retries: must be at most 5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &MemoryParamSource{Params: LoadedParams{
+				"/test/app/config":    tt.configJson,
+				"/test/app/databases": testDatabasesJson,
+			}}
+
+			config, err := LoadConfig(LoadOptions{
+				Env:         "test",
+				AppName:     "app",
+				Source:      source,
+				Secrets:     newTestResolver(&fakeSecretProvider{secrets: map[string]string{"payments": "sk_live"}}),
+				SkipSecrets: tt.skipSecrets,
+			})
+
+			if tt.wantErrs != nil {
+				var validationErrs ValidationErrors
+				if !errors.As(err, &validationErrs) {
+					t.Fatalf("LoadConfig() error = %v, want ValidationErrors", err)
+				}
+
+				var got []string
+				for _, e := range validationErrs {
+					got = append(got, e.Error())
+				}
+				if !slices.Equal(got, tt.wantErrs) {
+					t.Errorf("validation errors = %q, want %q", got, tt.wantErrs)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadConfig() error = %v", err)
+			}
+
+			got := testPaymentsSection.Get(config)
+			if got.Provider != tt.want.Provider || got.ApiKey != tt.want.ApiKey {
+				t.Errorf("Get() = %+v, want %+v", got, tt.want)
+			}
+			if (got.Retries == nil) != (tt.wantRetries == 0) || (got.Retries != nil && *got.Retries != tt.wantRetries) {
+				t.Errorf("Get().Retries = %v, want %v", got.Retries, tt.wantRetries)
+			}
+
+			if source := config.Sources[testPaymentsSection.Name()]; source != tt.wantSource {
+				t.Errorf("Sources[%v] = %q, want %q", testPaymentsSection.Name(), source, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestRegisterPanics(t *testing.T) {
+	for _, name := range []string{"test_payments", "port", sourceDb, sourceTimezone} {
+		t.Run(name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Register(%q) did not panic", name)
+				}
+			}()
+
+			Register[testPaymentsConfig](name)
+		})
+	}
+
+	registeredSectionsMu.RLock()
+	defer registeredSectionsMu.RUnlock()
+
+	if _, ok := registeredSections["port"]; ok {
+		t.Errorf("section %q registered despite the panic", "port")
+	}
+}
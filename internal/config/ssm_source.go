@@ -0,0 +1,207 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// SSM API hard limit
+const max_get_parameters_names = 10
+
+// SSMParamSource loads params from AWS SSM Parameter Store
+type SSMParamSource struct {
+	// Instead of fetching each param by name, fetch every param under the param path
+	// directories (e.g. /{env}/{app}/) with GetParametersByPath
+	ByPath bool
+
+	// Client used to call the SSM API. Built from AWSConfig if nil.
+	Client SSMClient
+
+	// AWS config used to build the client, defaults to the shared AWS config
+	AWSConfig *aws.Config
+
+	// Custom SSM API endpoint, e.g. a LocalStack URL
+	Endpoint string
+
+	// Param tag naming the format of a JSON, YAML or TOML param, e.g. format=yaml. Untagged
+	// params have their format sniffed. Tags are only read when set, at the cost of one
+	// ListTagsForResource call per param.
+	FormatTag string
+}
+
+// Interface to allow mocking of the AWS SSM API
+type SSMClient interface {
+	GetParameters(ctx context.Context, input *ssm.GetParametersInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersOutput, error)
+	GetParametersByPath(ctx context.Context, input *ssm.GetParametersByPathInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error)
+	ListTagsForResource(ctx context.Context, input *ssm.ListTagsForResourceInput, optFns ...func(*ssm.Options)) (*ssm.ListTagsForResourceOutput, error)
+}
+
+// MissingParamsError lists the requested params that don't exist in the source
+type MissingParamsError struct {
+	Names []string
+}
+
+func (m *MissingParamsError) Error() string {
+	return fmt.Sprintf("missing config params: [%v]", strings.Join(m.Names, ", "))
+}
+
+func (m *MissingParamsError) Is(target error) bool {
+	return target == ErrParamNotFound
+}
+
+func (s *SSMParamSource) Load(ctx context.Context, paramPaths ParamPaths) (loadedParams LoadedParams, err error) {
+	paramNames := make([]string, 0, len(paramPaths))
+
+	for _, v := range paramPaths {
+		paramNames = append(paramNames, v)
+	}
+	slices.Sort(paramNames)
+
+	ssmClient, err := s.client(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.ByPath {
+		loadedParams, err = loadSSMParamsByPath(ctx, ssmClient, paramNames)
+	} else {
+		loadedParams, err = loadSSMParamsByName(ctx, ssmClient, paramNames)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	missingNames := make([]string, 0)
+	for key, name := range paramPaths {
+		if _, ok := loadedParams[name]; !ok && !slices.Contains(optionalParamKeys, key) {
+			missingNames = append(missingNames, name)
+		}
+	}
+	slices.Sort(missingNames)
+
+	if len(missingNames) > 0 {
+		return nil, &MissingParamsError{Names: missingNames}
+	}
+
+	if s.FormatTag != "" {
+		err = convertTaggedSSMParams(ctx, ssmClient, s.FormatTag, paramNames, loadedParams)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return loadedParams, nil
+}
+
+func (s *SSMParamSource) client(ctx context.Context) (SSMClient, error) {
+	if s.Client != nil {
+		return s.Client, nil
+	}
+
+	cfg, err := awsConfigOrDefault(ctx, s.AWSConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return ssm.NewFromConfig(cfg, func(o *ssm.Options) {
+		if s.Endpoint != "" {
+			o.BaseEndpoint = aws.String(s.Endpoint)
+		}
+	}), nil
+}
+
+// convertTaggedSSMParams converts the params tagged with their format to JSON
+func convertTaggedSSMParams(ctx context.Context, ssmClient SSMClient, formatTag string, paramNames []string, loadedParams LoadedParams) error {
+	for _, name := range paramNames {
+		// missing optional params
+		if _, ok := loadedParams[name]; !ok {
+			continue
+		}
+
+		tagsOutput, err := ssmClient.ListTagsForResource(ctx, &ssm.ListTagsForResourceInput{
+			ResourceId:   aws.String(name),
+			ResourceType: types.ResourceTypeForTaggingParameter,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, tag := range tagsOutput.TagList {
+			if aws.ToString(tag.Key) != formatTag {
+				continue
+			}
+
+			format := Format(strings.ToLower(aws.ToString(tag.Value)))
+			paramJson, err := toJson(loadedParams[name], format)
+			if err != nil {
+				return fmt.Errorf("error decoding %v as %v: %w", name, format, err)
+			}
+
+			loadedParams[name] = paramJson
+		}
+	}
+
+	return nil
+}
+
+// loadSSMParamsByName fetches the params with GetParameters, in batches of the API name limit
+func loadSSMParamsByName(ctx context.Context, ssmClient SSMClient, paramNames []string) (LoadedParams, error) {
+	loadedParams := make(LoadedParams, len(paramNames))
+
+	for batch := range slices.Chunk(paramNames, max_get_parameters_names) {
+		ssmOutput, err := ssmClient.GetParameters(ctx, &ssm.GetParametersInput{
+			Names:          batch,
+			WithDecryption: aws.Bool(true),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, param := range ssmOutput.Parameters {
+			loadedParams[*param.Name] = *param.Value
+		}
+	}
+
+	return loadedParams, nil
+}
+
+// loadSSMParamsByPath fetches every param under the directories of paramNames with
+// GetParametersByPath, following the pagination
+func loadSSMParamsByPath(ctx context.Context, ssmClient SSMClient, paramNames []string) (LoadedParams, error) {
+	loadedParams := make(LoadedParams, len(paramNames))
+	treePaths := make([]string, 0, len(paramNames))
+
+	for _, name := range paramNames {
+		treePaths = append(treePaths, path.Dir(name))
+	}
+	slices.Sort(treePaths)
+	treePaths = slices.Compact(treePaths)
+
+	for _, treePath := range treePaths {
+		paginator := ssm.NewGetParametersByPathPaginator(ssmClient, &ssm.GetParametersByPathInput{
+			Path:           aws.String(treePath),
+			Recursive:      aws.Bool(true),
+			WithDecryption: aws.Bool(true),
+		})
+
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, param := range page.Parameters {
+				loadedParams[*param.Name] = *param.Value
+			}
+		}
+	}
+
+	return loadedParams, nil
+}
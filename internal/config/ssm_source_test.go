@@ -0,0 +1,239 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// fakeSSMClient serves params and their tags from maps, enforcing the API name limit and
+// paginating GetParametersByPath by pageSize
+type fakeSSMClient struct {
+	params   map[string]string
+	tags     map[string]map[string]string
+	pageSize int
+
+	batches []int
+	pages   int
+}
+
+func (f *fakeSSMClient) GetParameters(_ context.Context, input *ssm.GetParametersInput, _ ...func(*ssm.Options)) (*ssm.GetParametersOutput, error) {
+	if len(input.Names) > max_get_parameters_names {
+		return nil, fmt.Errorf("%v names over the API limit", len(input.Names))
+	}
+	f.batches = append(f.batches, len(input.Names))
+
+	output := &ssm.GetParametersOutput{}
+	for _, name := range input.Names {
+		if value, ok := f.params[name]; ok {
+			output.Parameters = append(output.Parameters, types.Parameter{Name: aws.String(name), Value: aws.String(value)})
+		} else {
+			output.InvalidParameters = append(output.InvalidParameters, name)
+		}
+	}
+
+	return output, nil
+}
+
+func (f *fakeSSMClient) GetParametersByPath(_ context.Context, input *ssm.GetParametersByPathInput, _ ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error) {
+	f.pages++
+
+	var names []string
+	for name := range f.params {
+		if strings.HasPrefix(name, aws.ToString(input.Path)+"/") {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	start := 0
+	if input.NextToken != nil {
+		start, _ = strconv.Atoi(*input.NextToken)
+	}
+	end := min(start+f.pageSize, len(names))
+
+	output := &ssm.GetParametersByPathOutput{}
+	for _, name := range names[start:end] {
+		output.Parameters = append(output.Parameters, types.Parameter{Name: aws.String(name), Value: aws.String(f.params[name])})
+	}
+	if end < len(names) {
+		output.NextToken = aws.String(strconv.Itoa(end))
+	}
+
+	return output, nil
+}
+
+func (f *fakeSSMClient) ListTagsForResource(_ context.Context, input *ssm.ListTagsForResourceInput, _ ...func(*ssm.Options)) (*ssm.ListTagsForResourceOutput, error) {
+	output := &ssm.ListTagsForResourceOutput{}
+	for key, value := range f.tags[aws.ToString(input.ResourceId)] {
+		output.TagList = append(output.TagList, types.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+
+	return output, nil
+}
+
+func TestSSMParamSourceByNameBatches(t *testing.T) {
+	client := &fakeSSMClient{params: map[string]string{}}
+	paramPaths := ParamPaths{}
+	for i := range 12 {
+		name := fmt.Sprintf("/test/app/param%02d", i)
+		client.params[name] = strconv.Itoa(i)
+		paramPaths[fmt.Sprintf("PARAM%02d_PATH", i)] = name
+	}
+
+	loadedParams, err := (&SSMParamSource{Client: client}).Load(context.Background(), paramPaths)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if len(loadedParams) != 12 {
+		t.Errorf("loaded %v params, want 12", len(loadedParams))
+	}
+	if !slices.Equal(client.batches, []int{10, 2}) {
+		t.Errorf("fetched in batches of %v, want [10 2]", client.batches)
+	}
+}
+
+func TestSSMParamSourceByPathPagination(t *testing.T) {
+	client := &fakeSSMClient{
+		pageSize: 2,
+		params: map[string]string{
+			"/test/app/config":      `{"port": 8081}`,
+			"/test/app/databases":   testDatabasesJson,
+			"/test/app/flags":       `{}`,
+			"/test/app/other":       "unrequested",
+			"/test/app/nested/leaf": "unrequested",
+			"/test/common/timezone": "UTC",
+			"/prod/app/config":      "other env",
+		},
+	}
+
+	loadedParams, err := (&SSMParamSource{Client: client, ByPath: true}).Load(context.Background(), buildParamPaths("test", "app"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	for _, name := range []string{"/test/app/config", "/test/app/databases", "/test/common/timezone"} {
+		if loadedParams[name] != client.params[name] {
+			t.Errorf("loadedParams[%q] = %q, want %q", name, loadedParams[name], client.params[name])
+		}
+	}
+
+	// 5 params under /test/app in pages of 2, then 1 under /test/common
+	if client.pages != 4 {
+		t.Errorf("fetched %v pages, want 4", client.pages)
+	}
+	if _, ok := loadedParams["/prod/app/config"]; ok {
+		t.Errorf("loaded a param outside the requested trees")
+	}
+}
+
+func TestSSMParamSourceMissingParams(t *testing.T) {
+	tests := []struct {
+		name        string
+		params      map[string]string
+		wantMissing []string
+	}{
+		{
+			name: "every param",
+			params: map[string]string{
+				"/test/app/config":      `{}`,
+				"/test/app/databases":   testDatabasesJson,
+				"/test/common/timezone": "UTC",
+			},
+		},
+		{
+			name: "missing timezone is optional",
+			params: map[string]string{
+				"/test/app/config":    `{}`,
+				"/test/app/databases": testDatabasesJson,
+			},
+		},
+		{
+			name:        "missing required params",
+			params:      map[string]string{"/test/common/timezone": "UTC"},
+			wantMissing: []string{"/test/app/config", "/test/app/databases"},
+		},
+	}
+
+	for _, tt := range tests {
+		for _, byPath := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%v by path %v", tt.name, byPath), func(t *testing.T) {
+				client := &fakeSSMClient{params: tt.params, pageSize: 10}
+				source := &SSMParamSource{Client: client, ByPath: byPath, FormatTag: "format"}
+
+				_, err := source.Load(context.Background(), buildParamPaths("test", "app"))
+
+				var missingErr *MissingParamsError
+				if tt.wantMissing == nil {
+					if err != nil {
+						t.Fatalf("Load() error = %v", err)
+					}
+					return
+				}
+
+				if !errors.As(err, &missingErr) {
+					t.Fatalf("Load() error = %v, want MissingParamsError", err)
+				}
+				if !slices.Equal(missingErr.Names, tt.wantMissing) {
+					t.Errorf("missing params = %v, want %v", missingErr.Names, tt.wantMissing)
+				}
+				if !errors.Is(err, ErrParamNotFound) {
+					t.Errorf("Load() error = %v, want it to match ErrParamNotFound", err)
+				}
+			})
+		}
+	}
+}
+
+func TestSSMParamSourceFormatTag(t *testing.T) {
+	client := &fakeSSMClient{
+		params: map[string]string{
+			"/test/app/config":    "log_level = \"debug\"",
+			"/test/app/databases": "[main]\ndatabase = \"db\"\nport = 3306",
+		},
+		tags: map[string]map[string]string{
+			"/test/app/config": {"format": "toml"},
+		},
+	}
+	source := &SSMParamSource{Client: client, FormatTag: "format"}
+
+	loadedParams, err := source.Load(context.Background(), ParamPaths{
+		"CONFIG_PATH":    "/test/app/config",
+		"DATABASES_PATH": "/test/app/databases",
+	})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/test/app/config", `{"log_level":"debug"}`},
+		{"/test/app/databases", "[main]\ndatabase = \"db\"\nport = 3306"},
+	}
+
+	for _, tt := range tests {
+		if got := loadedParams[tt.path]; got != tt.want {
+			t.Errorf("loadedParams[%q] = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+
+	for _, format := range []string{"xml", "json"} {
+		client.tags["/test/app/config"]["format"] = format
+
+		_, err = source.Load(context.Background(), ParamPaths{"CONFIG_PATH": "/test/app/config"})
+		if err == nil {
+			t.Errorf("Load() of a toml param tagged %v succeeded", format)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+var (
+	hostnameLabelRegex = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+	plainKeyRegex      = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+)
+
+// ValidationError is a single rule violation, located by the json path of the field
+type ValidationError struct {
+	Path    string
+	Rule    string
+	Message string
+}
+
+func (v ValidationError) Error() string {
+	return fmt.Sprintf("%v: %v", v.Path, v.Message)
+}
+
+// ValidationErrors holds every violation found in a validated value
+type ValidationErrors []ValidationError
+
+func (v ValidationErrors) Error() string {
+	lines := make([]string, 0, len(v)+1)
+	lines = append(lines, "invalid config:")
+
+	for _, e := range v {
+		lines = append(lines, "  "+e.Error())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Validate checks v against the rules declared in its `validate` struct tags and
+// returns a ValidationErrors with every violation, or nil.
+//
+// Supported rules: required, min=N, max=N, oneof=a b c, hostname, port, timezone and dive,
+// which applies the rules after it to each element of a slice or map.
+func Validate(v any) error {
+	var errs ValidationErrors
+
+	validateAt(v, "", &errs)
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+// validateAt validates v, prefixing the violation paths with path
+func validateAt(v any, path string, errs *ValidationErrors) {
+	validateValue(reflect.ValueOf(v), path, errs)
+}
+
+func validateValue(value reflect.Value, path string, errs *ValidationErrors) {
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !value.IsNil() {
+			validateValue(value.Elem(), path, errs)
+		}
+
+	case reflect.Struct:
+		valueType := value.Type()
+		for i := range valueType.NumField() {
+			field := valueType.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			fieldPath := joinPath(path, fieldName(field))
+			rules := field.Tag.Get("validate")
+			if rules == "-" {
+				continue
+			}
+
+			validateRules(value.Field(i), fieldPath, rules, errs)
+			validateValue(value.Field(i), fieldPath, errs)
+		}
+
+	case reflect.Map:
+		keys := value.MapKeys()
+		slices.SortFunc(keys, func(a, b reflect.Value) int {
+			return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+		})
+
+		for _, key := range keys {
+			validateValue(value.MapIndex(key), mapKeyPath(path, key), errs)
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := range value.Len() {
+			validateValue(value.Index(i), fmt.Sprintf("%v[%v]", path, i), errs)
+		}
+	}
+}
+
+func validateRules(value reflect.Value, path string, rules string, errs *ValidationErrors) {
+	if rules == "" {
+		return
+	}
+
+	ruleList := strings.Split(rules, ",")
+	for i, rule := range ruleList {
+		name, param, _ := strings.Cut(rule, "=")
+
+		if name == "dive" {
+			elemRules := strings.Join(ruleList[i+1:], ",")
+			validateElements(value, path, elemRules, errs)
+			return
+		}
+
+		message, ok := checkRule(value, name, param)
+		if !ok {
+			*errs = append(*errs, ValidationError{Path: path, Rule: name, Message: message})
+
+			// the remaining rules are meaningless for a missing value
+			if name == "required" {
+				return
+			}
+		}
+	}
+}
+
+func validateElements(value reflect.Value, path string, rules string, errs *ValidationErrors) {
+	switch value.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := range value.Len() {
+			validateRules(value.Index(i), fmt.Sprintf("%v[%v]", path, i), rules, errs)
+		}
+
+	case reflect.Map:
+		for _, key := range value.MapKeys() {
+			validateRules(value.MapIndex(key), mapKeyPath(path, key), rules, errs)
+		}
+	}
+}
+
+func checkRule(value reflect.Value, name string, param string) (string, bool) {
+	// optional values are only checked when set
+	if value.Kind() == reflect.Pointer && name != "required" {
+		if value.IsNil() {
+			return "", true
+		}
+		value = value.Elem()
+	}
+
+	switch name {
+	case "required":
+		return "is required", !value.IsZero()
+
+	case "min", "max":
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return fmt.Sprintf("invalid %v rule param %q", name, param), false
+		}
+
+		size, sizeLabel := measure(value)
+		if name == "min" && size < limit {
+			return fmt.Sprintf("must be at least %v%v", param, sizeLabel), false
+		}
+		if name == "max" && size > limit {
+			return fmt.Sprintf("must be at most %v%v", param, sizeLabel), false
+		}
+
+		return "", true
+
+	case "oneof":
+		options := strings.Fields(param)
+		return fmt.Sprintf("must be one of [%v]", strings.Join(options, ", ")), slices.Contains(options, fmt.Sprint(value.Interface()))
+
+	case "hostname":
+		return "must be a valid hostname or IP address", isHostname(fmt.Sprint(value.Interface()))
+
+	case "timezone":
+		_, err := loadLocation(fmt.Sprint(value.Interface()))
+		return "must be a valid IANA timezone, e.g. America/Sao_Paulo", err == nil
+
+	case "port":
+		port, err := strconv.Atoi(fmt.Sprint(value.Interface()))
+		return "must be a port number between 1 and 65535", err == nil && port >= 1 && port <= 65535
+
+	default:
+		return fmt.Sprintf("unknown validation rule %q", name), false
+	}
+}
+
+// measure returns the number compared by min/max: the value itself for numbers,
+// the length for strings, slices and maps
+func measure(value reflect.Value) (float64, string) {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), ""
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), ""
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), ""
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map:
+		return float64(value.Len()), " in length"
+	default:
+		return 0, ""
+	}
+}
+
+func isHostname(host string) bool {
+	if net.ParseIP(host) != nil {
+		return true
+	}
+
+	if len(host) == 0 || len(host) > 253 {
+		return false
+	}
+
+	for _, label := range strings.Split(strings.TrimSuffix(host, "."), ".") {
+		if !hostnameLabelRegex.MatchString(label) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func fieldName(field reflect.StructField) string {
+	if name := jsonFieldName(field); name != "" {
+		return name
+	}
+
+	return field.Name
+}
+
+func joinPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+// mapKeyPath quotes keys that are not plain identifiers, so stray whitespace shows up
+func mapKeyPath(path string, key reflect.Value) string {
+	keyString := fmt.Sprint(key.Interface())
+	if plainKeyRegex.MatchString(keyString) {
+		return joinPath(path, keyString)
+	}
+
+	return fmt.Sprintf("%v[%q]", path, keyString)
+}
@@ -0,0 +1,185 @@
+package config
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func validDbConnConfig() DbConnConfig {
+	var db DbConnConfig
+
+	db.Database = "db"
+	db.Host.Write = "localhost"
+	db.Host.Read = []string{"10.0.0.1"}
+	db.Port = "3306"
+	db.Username = "user"
+
+	return db
+}
+
+func validConfig() Config {
+	return Config{
+		AppName:   "app",
+		Env:       "test",
+		Timezone:  "UTC",
+		Port:      8080,
+		LogLevel:  "info",
+		LogFormat: "json",
+		Db:        Db{"main": validDbConnConfig()},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   []string
+	}{
+		{
+			name:   "valid",
+			modify: func(c *Config) {},
+		},
+		{
+			name: "missing required fields",
+			modify: func(c *Config) {
+				c.AppName = ""
+				c.LogLevel = ""
+			},
+			want: []string{"AppName: is required", "log_level: is required"},
+		},
+		{
+			name: "port out of range",
+			modify: func(c *Config) {
+				c.Port = 70000
+			},
+			want: []string{"port: must be at most 65535"},
+		},
+		{
+			name: "unknown log level and timezone",
+			modify: func(c *Config) {
+				c.LogLevel = "loud"
+				c.Timezone = "Mars/Olympus"
+			},
+			want: []string{
+				"Timezone: must be a valid IANA timezone, e.g. America/Sao_Paulo",
+				"log_level: must be one of [trace, debug, info, warn, error, critical, fatal]",
+			},
+		},
+		{
+			name: "every db violation with its path",
+			modify: func(c *Config) {
+				db := validDbConnConfig()
+				db.Host.Write = ""
+				db.Host.Read = []string{"10.0.0.1", "bad host"}
+				db.Port = "99999"
+				c.Db["users "] = db
+			},
+			want: []string{
+				`Db["users "].host.read[1]: must be a valid hostname or IP address`,
+				`Db["users "].host.write: is required`,
+				`Db["users "].port: must be a port number between 1 and 65535`,
+			},
+		},
+		{
+			name: "optional log output fields only checked when set",
+			modify: func(c *Config) {
+				format := "xml"
+				c.LogOutputs = []LogOutput{{Type: "stdout"}, {Type: "stderr", Format: &format}}
+			},
+			want: []string{"log_outputs[1].format: must be one of [json, text, pretty]"},
+		},
+		{
+			name: "log sampling and dedup",
+			modify: func(c *Config) {
+				level, window := "loud", 0
+				c.LogSampling = &LogSampling{Default: &LogSamplingRule{First: -1}}
+				c.LogDedup = &LogDedup{WindowMs: &window, Level: &level}
+			},
+			want: []string{
+				"log_dedup.level: must be one of [trace, debug, info, warn, error, critical, fatal]",
+				"log_dedup.window_ms: must be at least 1",
+				"log_sampling.default.first: must be at least 0",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(&cfg)
+
+			err := Validate(&cfg)
+
+			var got []string
+			var validationErrs ValidationErrors
+			if errors.As(err, &validationErrs) {
+				for _, e := range validationErrs {
+					got = append(got, e.Error())
+				}
+			} else if err != nil {
+				t.Fatalf("Validate() error = %v, want ValidationErrors", err)
+			}
+
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Validate() errors = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigLogFields(t *testing.T) {
+	tests := []struct {
+		name       string
+		configJson string
+		want       []string
+	}{
+		{"file output with its log file", `{"log_outputs": [{"type": "file"}], "log_file": {"path": "app.log"}}`, nil},
+		{"log file as the only output", `{"log_file": {"path": "app.log"}}`, nil},
+		{
+			"file output without a log file",
+			`{"log_outputs": [{"type": "stdout"}, {"type": "file"}]}`,
+			[]string{"log_outputs[1]: file output requires log_file"},
+		},
+		{
+			"log file without a file output",
+			`{"log_outputs": [{"type": "stdout"}], "log_file": {"path": "app.log"}}`,
+			[]string{"log_file: is unused, no log_outputs entry is of type file"},
+		},
+		{"sampled levels", `{"log_sampling": {"levels": {"debug": {"first": 1}, "warn": null}}}`, nil},
+		{
+			"unsampled and unknown levels",
+			`{"log_sampling": {"levels": {"error": {"first": 1}, "loud": {"first": 1}}}}`,
+			[]string{
+				"log_sampling.levels.error: error and above records are never sampled",
+				"log_sampling.levels.loud: must be one of [trace, debug, info, warn]",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := &MemoryParamSource{Params: LoadedParams{
+				"/test/app/config":    tt.configJson,
+				"/test/app/databases": testDatabasesJson,
+			}}
+
+			_, err := LoadConfig(LoadOptions{Env: "test", AppName: "app", Source: source, SkipSecrets: true})
+
+			var got []string
+			var validationErrs ValidationErrors
+			if errors.As(err, &validationErrs) {
+				for _, e := range validationErrs {
+					got = append(got, e.Error())
+				}
+			} else if err != nil {
+				t.Fatalf("LoadConfig() error = %v, want ValidationErrors", err)
+			}
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("LoadConfig() errors = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bermr/api-golang-base/internal/tools/clock"
+)
+
+// Default reload delays. Local files are cheap to re-read, remote sources are not.
+const (
+	default_local_watcher_ms_delay  = 2 * 1000
+	default_remote_watcher_ms_delay = 5 * 60 * 1000
+)
+
+type WatcherOptions struct {
+	// Options used on every load
+	Load LoadOptions
+
+	// Time between reloads
+	WatcherDelay *int
+
+	// Drives the reloads, defaults to the system clock
+	Clock clock.Clock
+}
+
+// Subscriber is notified with the previous and the new snapshot whenever the config changes.
+// Snapshots must not be mutated, e.g. to rebuild database pools compare prev.Db and next.Db.
+type Subscriber func(prev *Config, next *Config)
+
+// Watcher periodically reloads the config from its source and atomically publishes
+// each changed snapshot to its subscribers
+type Watcher struct {
+	current     atomic.Pointer[Config]
+	loadOpts    LoadOptions
+	subscribers map[int]Subscriber
+	nextSubId   int
+	ticker      clock.Ticker
+	reloadMu    sync.Mutex
+	mu          sync.Mutex
+
+	// stops the reload goroutine, cancelling an ongoing reload
+	ctx       context.Context
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+}
+
+func NewWatcher(opts WatcherOptions) (*Watcher, error) {
+	var watcherDelay int
+
+	err := opts.Load.Resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.WatcherDelay == nil {
+		watcherDelay = defaultWatcherDelay(opts.Load.Source)
+	} else {
+		watcherDelay = *opts.WatcherDelay
+	}
+
+	if opts.Clock == nil {
+		opts.Clock = clock.New(nil)
+	}
+
+	config, err := LoadConfig(opts.Load)
+	if err != nil {
+		return nil, err
+	}
+
+	watcher := &Watcher{
+		loadOpts:    opts.Load,
+		subscribers: make(map[int]Subscriber),
+		ticker:      opts.Clock.NewTicker(time.Millisecond * time.Duration(watcherDelay)),
+	}
+	watcher.current.Store(config)
+	watcher.ctx, watcher.cancel = context.WithCancel(context.Background())
+
+	go func() {
+		for {
+			select {
+			case <-watcher.ctx.Done():
+				return
+			case <-watcher.ticker.C():
+				err := watcher.Reload(watcher.ctx)
+				if err != nil && watcher.ctx.Err() == nil {
+					slog.Error("config reload failed, keeping the current config", "err", err)
+				}
+			}
+		}
+	}()
+
+	return watcher, nil
+}
+
+// Current returns the latest config snapshot
+func (w *Watcher) Current() *Config {
+	return w.current.Load()
+}
+
+// Subscribe registers fn to be called on every config change and returns a function that
+// removes it
+func (w *Watcher) Subscribe(fn Subscriber) (unsubscribe func()) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	id := w.nextSubId
+	w.nextSubId++
+	w.subscribers[id] = fn
+
+	return func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+
+		delete(w.subscribers, id)
+	}
+}
+
+// Reload loads the config from the source and, if it changed, publishes it. An invalid
+// config is never published.
+func (w *Watcher) Reload(ctx context.Context) error {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	loadOpts := w.loadOpts
+	loadOpts.Context = ctx
+
+	next, err := LoadConfig(loadOpts)
+	if err != nil {
+		return err
+	}
+
+	prev := w.current.Load()
+	if reflect.DeepEqual(prev, next) {
+		return nil
+	}
+
+	w.current.Store(next)
+
+	w.mu.Lock()
+	subscribers := make([]Subscriber, 0, len(w.subscribers))
+	for _, fn := range w.subscribers {
+		subscribers = append(subscribers, fn)
+	}
+	w.mu.Unlock()
+
+	for _, fn := range subscribers {
+		fn(prev, next)
+	}
+
+	return nil
+}
+
+// Close stops the reloads. It can be called more than once.
+func (w *Watcher) Close() error {
+	w.closeOnce.Do(func() {
+		w.ticker.Stop()
+		w.cancel()
+	})
+
+	return nil
+}
+
+func defaultWatcherDelay(source ParamSource) int {
+	switch source.(type) {
+	case *LocalParamSource, *MemoryParamSource, *EnvParamSource:
+		return default_local_watcher_ms_delay
+	default:
+		return default_remote_watcher_ms_delay
+	}
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/bermr/api-golang-base/internal/tools/clock"
+)
+
+const testDatabasesJson = `{"main": {"database": "db", "host": {"write": "localhost"}, "port": "3306", "username": "user"}}`
+
+func newTestWatcher(t *testing.T, source *MemoryParamSource) *Watcher {
+	t.Helper()
+
+	// only reloaded by hand
+	watcherDelay := 60 * 60 * 1000
+
+	watcher, err := NewWatcher(WatcherOptions{
+		Load:         LoadOptions{Env: "test", AppName: "app", Source: source, SkipSecrets: true},
+		WatcherDelay: &watcherDelay,
+	})
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+	t.Cleanup(func() { watcher.Close() })
+
+	return watcher
+}
+
+func TestWatcherReload(t *testing.T) {
+	source := &MemoryParamSource{Params: LoadedParams{
+		"/test/app/config":    `{"port": 8081, "log_level": "info"}`,
+		"/test/app/databases": testDatabasesJson,
+	}}
+	watcher := newTestWatcher(t, source)
+
+	var notified []string
+	unsubscribe := watcher.Subscribe(func(prev, next *Config) {
+		notified = append(notified, prev.LogLevel+"->"+next.LogLevel)
+	})
+
+	tests := []struct {
+		name         string
+		configJson   string
+		wantErr      bool
+		wantLevel    string
+		wantNotified int
+	}{
+		{"unchanged config is not published", `{"port": 8081, "log_level": "info"}`, false, "info", 0},
+		{"changed config is published", `{"port": 8081, "log_level": "debug"}`, false, "debug", 1},
+		{"invalid config is not published", `{"port": 8081, "log_level": "loud"}`, true, "debug", 1},
+		{"undecodable config is not published", `{"port": "x"}`, true, "debug", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source.Params["/test/app/config"] = tt.configJson
+
+			err := watcher.Reload(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Reload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got := watcher.Current().LogLevel; got != tt.wantLevel {
+				t.Errorf("Current().LogLevel = %q, want %q", got, tt.wantLevel)
+			}
+			if len(notified) != tt.wantNotified {
+				t.Errorf("notified %v times, want %v", len(notified), tt.wantNotified)
+			}
+		})
+	}
+
+	if notified[0] != "info->debug" {
+		t.Errorf("subscriber got %q, want info->debug", notified[0])
+	}
+
+	unsubscribe()
+	source.Params["/test/app/config"] = `{"port": 8081, "log_level": "warn"}`
+
+	err := watcher.Reload(context.Background())
+	if err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if len(notified) != 1 {
+		t.Errorf("unsubscribed subscriber notified")
+	}
+}
+
+func TestWatcherReloadsOnTick(t *testing.T) {
+	source := &MemoryParamSource{Params: LoadedParams{
+		"/test/app/config":    `{"port": 8081, "log_level": "info"}`,
+		"/test/app/databases": testDatabasesJson,
+	}}
+	fake := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	watcherDelay := 1000
+
+	watcher, err := NewWatcher(WatcherOptions{
+		Load:         LoadOptions{Env: "test", AppName: "app", Source: source, SkipSecrets: true},
+		WatcherDelay: &watcherDelay,
+		Clock:        fake,
+	})
+	if err != nil {
+		t.Fatalf("NewWatcher() error = %v", err)
+	}
+	defer watcher.Close()
+
+	reloaded := make(chan string, 1)
+	watcher.Subscribe(func(prev, next *Config) {
+		reloaded <- next.LogLevel
+	})
+
+	source.Params["/test/app/config"] = `{"port": 8081, "log_level": "debug"}`
+
+	fake.Advance(999 * time.Millisecond)
+	select {
+	case <-reloaded:
+		t.Fatal("reloaded before the watcher delay")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	fake.Advance(time.Millisecond)
+	select {
+	case level := <-reloaded:
+		if level != "debug" {
+			t.Errorf("reloaded log_level = %q, want debug", level)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("not reloaded after the watcher delay")
+	}
+}
+
+func TestWatcherCloseTwice(t *testing.T) {
+	watcher := newTestWatcher(t, &MemoryParamSource{Params: LoadedParams{
+		"/test/app/databases": testDatabasesJson,
+	}})
+
+	for range 2 {
+		err := watcher.Close()
+		if err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	}
+
+	if watcher.ctx.Err() == nil {
+		t.Errorf("reload goroutine context not cancelled by Close")
+	}
+}
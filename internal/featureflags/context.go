@@ -0,0 +1,51 @@
+package featureflags
+
+import (
+	"context"
+	"net/http"
+)
+
+// Header carrying the user id used for user targeting and rollouts
+const user_id_header = "X-User-Id"
+
+type ctxKey struct{}
+
+// EvalContext holds the request data flags are evaluated against
+type EvalContext struct {
+	RequestId string
+	UserId    string
+	Headers   http.Header
+}
+
+// NewEvalContext builds the evaluation context of a request
+func NewEvalContext(r *http.Request, requestId string) *EvalContext {
+	return &EvalContext{
+		RequestId: requestId,
+		UserId:    r.Header.Get(user_id_header),
+		Headers:   r.Header.Clone(),
+	}
+}
+
+// NewContext returns a copy of ctx carrying evalCtx
+func NewContext(ctx context.Context, evalCtx *EvalContext) context.Context {
+	return context.WithValue(ctx, ctxKey{}, evalCtx)
+}
+
+// FromContext returns the evaluation context carried by ctx, or an empty one
+func FromContext(ctx context.Context) *EvalContext {
+	if evalCtx, ok := ctx.Value(ctxKey{}).(*EvalContext); ok {
+		return evalCtx
+	}
+
+	return &EvalContext{Headers: http.Header{}}
+}
+
+// rolloutKey returns the key that places the request in a rollout bucket: the user if known,
+// the request otherwise
+func (e *EvalContext) rolloutKey() string {
+	if e.UserId != "" {
+		return e.UserId
+	}
+
+	return e.RequestId
+}
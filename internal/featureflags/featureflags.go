@@ -0,0 +1,138 @@
+package featureflags
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/bermr/api-golang-base/internal/config"
+	"github.com/bermr/api-golang-base/internal/tools/my_logger"
+)
+
+// Default time between flag refreshes
+const default_watcher_ms_delay = 30 * 1000
+
+type Options struct {
+	// Options used to load the flags, from the /{env}/{app}/flags param of the config source
+	Load config.LoadOptions
+
+	// Time between flag refreshes
+	WatcherDelay *int
+}
+
+// Flags evaluates feature flags, refreshing their definitions in the background
+type Flags struct {
+	flags     atomic.Pointer[map[string]Flag]
+	loadOpts  config.LoadOptions
+	flagsPath string
+	ticker    *time.Ticker
+
+	// stops the refresh goroutine, cancelling an ongoing refresh
+	ctx       context.Context
+	cancel    context.CancelFunc
+	closeOnce sync.Once
+}
+
+// New loads the flag definitions and starts refreshing them. The returned Flags is always
+// usable: if the first load fails, every flag is off until a refresh succeeds.
+func New(opts Options) (*Flags, error) {
+	var watcherDelay int
+
+	if opts.WatcherDelay == nil {
+		watcherDelay = default_watcher_ms_delay
+	} else {
+		watcherDelay = *opts.WatcherDelay
+	}
+
+	flags := &Flags{
+		ticker: time.NewTicker(time.Millisecond * time.Duration(watcherDelay)),
+	}
+	flags.flags.Store(&map[string]Flag{})
+	flags.ctx, flags.cancel = context.WithCancel(context.Background())
+
+	err := opts.Load.Resolve()
+	if err != nil {
+		flags.Close()
+		return flags, err
+	}
+
+	flags.loadOpts = opts.Load
+	flags.flagsPath = fmt.Sprintf("/%v/%v/flags", opts.Load.Env, opts.Load.AppName)
+
+	go func() {
+		for {
+			select {
+			case <-flags.ctx.Done():
+				return
+			case <-flags.ticker.C:
+				err := flags.Refresh(flags.ctx)
+				if err != nil && flags.ctx.Err() == nil {
+					slog.Error("feature flags refresh failed, keeping the current flags", "err", err)
+				}
+			}
+		}
+	}()
+
+	return flags, flags.Refresh(opts.Load.Context)
+}
+
+// Enabled reports whether the flag name is on for the request in ctx. Unknown flags are off.
+// Every decision is logged at debug level by the request logger.
+func (f *Flags) Enabled(ctx context.Context, name string) bool {
+	evalCtx := FromContext(ctx)
+
+	enabled, reason := false, reasonUnknown
+	if flag, ok := (*f.flags.Load())[name]; ok {
+		enabled, reason = flag.evaluate(name, evalCtx)
+	}
+
+	my_logger.FromContext(ctx).Log(ctx, "debug", "feature flag evaluated", "flag", name, "enabled", enabled, "reason", reason)
+
+	return enabled
+}
+
+// Refresh reloads the flag definitions. Invalid definitions are never applied. A missing
+// flags param means there are no flags.
+func (f *Flags) Refresh(ctx context.Context) error {
+	var flags map[string]Flag
+
+	ctx, cancel := context.WithTimeout(ctx, time.Millisecond*time.Duration(*f.loadOpts.Timeout))
+	defer cancel()
+
+	loadedParams, err := f.loadOpts.Source.Load(ctx, config.ParamPaths{"FLAGS_PATH": f.flagsPath})
+	if errors.Is(err, config.ErrParamNotFound) {
+		f.flags.Store(&map[string]Flag{})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	err = config.DecodePayload(f.flagsPath, loadedParams[f.flagsPath], &flags)
+	if err != nil {
+		return err
+	}
+
+	err = config.Validate(flags)
+	if err != nil {
+		return err
+	}
+
+	f.flags.Store(&flags)
+
+	return nil
+}
+
+// Close stops the refreshes. It can be called more than once.
+func (f *Flags) Close() error {
+	f.closeOnce.Do(func() {
+		f.ticker.Stop()
+		f.cancel()
+	})
+
+	return nil
+}
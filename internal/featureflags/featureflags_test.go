@@ -0,0 +1,118 @@
+package featureflags
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bermr/api-golang-base/internal/config"
+)
+
+func newTestFlags(t *testing.T, source *config.MemoryParamSource) *Flags {
+	t.Helper()
+
+	// only refreshed by hand
+	watcherDelay := 60 * 60 * 1000
+
+	flags, err := New(Options{
+		Load:         config.LoadOptions{Env: "test", AppName: "app", Source: source},
+		WatcherDelay: &watcherDelay,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { flags.Close() })
+
+	return flags
+}
+
+func TestFlagsRefresh(t *testing.T) {
+	source := &config.MemoryParamSource{Params: config.LoadedParams{
+		"/test/app/flags": `{"beta": {"enabled": true}}`,
+	}}
+	flags := newTestFlags(t, source)
+
+	tests := []struct {
+		name      string
+		flagsJson string
+		wantErr   bool
+		wantBeta  bool
+	}{
+		{"flag turned off", `{"beta": {"enabled": false}}`, false, false},
+		{"yaml flags", "beta:\n  enabled: true", false, true},
+		{"invalid flags are not applied", `{"beta": {"enabled": false, "rollout": 101}}`, true, true},
+		{"flag removed", `{}`, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source.Params["/test/app/flags"] = tt.flagsJson
+
+			err := flags.Refresh(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Refresh() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if got := flags.Enabled(context.Background(), "beta"); got != tt.wantBeta {
+				t.Errorf("Enabled(beta) = %v, want %v", got, tt.wantBeta)
+			}
+		})
+	}
+}
+
+func TestFlagsMissingParam(t *testing.T) {
+	dir := t.TempDir()
+	flagsFile := filepath.Join(dir, ".flags.json")
+	watcherDelay := 60 * 60 * 1000
+
+	flags, err := New(Options{
+		Load:         config.LoadOptions{Env: "test", AppName: "app", Source: &config.LocalParamSource{Dirs: []string{dir}}},
+		WatcherDelay: &watcherDelay,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { flags.Close() })
+
+	err = os.WriteFile(flagsFile, []byte(`{"beta": {"enabled": true}}`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = flags.Refresh(context.Background())
+	if err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+	if !flags.Enabled(context.Background(), "beta") {
+		t.Fatalf("Enabled(beta) = false, want true")
+	}
+
+	err = os.Remove(flagsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = flags.Refresh(context.Background())
+	if err != nil {
+		t.Fatalf("Refresh() error = %v, want a missing param to mean no flags", err)
+	}
+	if flags.Enabled(context.Background(), "beta") {
+		t.Errorf("Enabled(beta) = true, want false once the flags param is gone")
+	}
+}
+
+func TestFlagsCloseTwice(t *testing.T) {
+	flags := newTestFlags(t, &config.MemoryParamSource{Params: config.LoadedParams{}})
+
+	for range 2 {
+		err := flags.Close()
+		if err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	}
+
+	if flags.ctx.Err() == nil {
+		t.Errorf("refresh goroutine context not cancelled by Close")
+	}
+}
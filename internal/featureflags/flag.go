@@ -0,0 +1,75 @@
+package featureflags
+
+import (
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"slices"
+)
+
+// Flag is the definition of a feature flag, e.g.
+//
+//	"new-checkout": {
+//	  "enabled": true,
+//	  "rollout": 25,
+//	  "users": ["42"],
+//	  "headers": {"X-Beta": ["1"]}
+//	}
+//
+// A disabled flag is always off. Otherwise, it is on for targeted users and requests with a
+// targeted header value, then for the rollout percentage of the rest. A flag without
+// targeting or rollout is on for everyone.
+type Flag struct {
+	Enabled bool                `json:"enabled"`
+	Rollout *int                `json:"rollout" validate:"min=0,max=100"`
+	Users   []string            `json:"users"`
+	Headers map[string][]string `json:"headers"`
+}
+
+// Reasons of a flag decision, logged with it
+const (
+	reasonUnknown  = "unknown"
+	reasonDisabled = "disabled"
+	reasonUser     = "user"
+	reasonHeader   = "header"
+	reasonRollout  = "rollout"
+	reasonDefault  = "default"
+)
+
+func (f *Flag) evaluate(name string, evalCtx *EvalContext) (bool, string) {
+	if !f.Enabled {
+		return false, reasonDisabled
+	}
+
+	if evalCtx.UserId != "" && slices.Contains(f.Users, evalCtx.UserId) {
+		return true, reasonUser
+	}
+
+	for header, values := range f.Headers {
+		// an absent header must not match an empty targeted value
+		if _, ok := evalCtx.Headers[http.CanonicalHeaderKey(header)]; !ok {
+			continue
+		}
+
+		if slices.Contains(values, evalCtx.Headers.Get(header)) {
+			return true, reasonHeader
+		}
+	}
+
+	if f.Rollout != nil {
+		return rolloutBucket(name, evalCtx.rolloutKey()) < *f.Rollout, reasonRollout
+	}
+
+	targeted := len(f.Users) > 0 || len(f.Headers) > 0
+
+	return !targeted, reasonDefault
+}
+
+// rolloutBucket deterministically places key in one of 100 buckets, so a user keeps the same
+// decision while the rollout percentage doesn't change
+func rolloutBucket(name string, key string) int {
+	hash := fnv.New32a()
+	fmt.Fprintf(hash, "%v:%v", name, key)
+
+	return int(hash.Sum32() % 100)
+}
@@ -0,0 +1,101 @@
+package featureflags
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestFlagEvaluate(t *testing.T) {
+	rollout0, rollout100 := 0, 100
+
+	tests := []struct {
+		name       string
+		flag       Flag
+		evalCtx    *EvalContext
+		wantOn     bool
+		wantReason string
+	}{
+		{
+			name:       "disabled",
+			flag:       Flag{Enabled: false, Users: []string{"42"}},
+			evalCtx:    &EvalContext{UserId: "42"},
+			wantReason: reasonDisabled,
+		},
+		{
+			name:       "targeted user",
+			flag:       Flag{Enabled: true, Users: []string{"42"}},
+			evalCtx:    &EvalContext{UserId: "42"},
+			wantOn:     true,
+			wantReason: reasonUser,
+		},
+		{
+			name:       "targeted header value",
+			flag:       Flag{Enabled: true, Headers: map[string][]string{"x-beta": {"1"}}},
+			evalCtx:    &EvalContext{Headers: http.Header{"X-Beta": {"1"}}},
+			wantOn:     true,
+			wantReason: reasonHeader,
+		},
+		{
+			name:       "empty targeted header value with the header present",
+			flag:       Flag{Enabled: true, Headers: map[string][]string{"X-Beta": {""}}},
+			evalCtx:    &EvalContext{Headers: http.Header{"X-Beta": {""}}},
+			wantOn:     true,
+			wantReason: reasonHeader,
+		},
+		{
+			name:       "empty targeted header value with the header absent",
+			flag:       Flag{Enabled: true, Headers: map[string][]string{"X-Beta": {""}}},
+			evalCtx:    &EvalContext{Headers: http.Header{}},
+			wantReason: reasonDefault,
+		},
+		{
+			name:       "untargeted request",
+			flag:       Flag{Enabled: true, Users: []string{"42"}},
+			evalCtx:    &EvalContext{UserId: "7"},
+			wantReason: reasonDefault,
+		},
+		{
+			name:       "full rollout",
+			flag:       Flag{Enabled: true, Rollout: &rollout100},
+			evalCtx:    &EvalContext{UserId: "7"},
+			wantOn:     true,
+			wantReason: reasonRollout,
+		},
+		{
+			name:       "no rollout",
+			flag:       Flag{Enabled: true, Rollout: &rollout0},
+			evalCtx:    &EvalContext{UserId: "7"},
+			wantReason: reasonRollout,
+		},
+		{
+			name:       "no targeting",
+			flag:       Flag{Enabled: true},
+			evalCtx:    &EvalContext{},
+			wantOn:     true,
+			wantReason: reasonDefault,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			on, reason := tt.flag.evaluate("flag", tt.evalCtx)
+			if on != tt.wantOn || reason != tt.wantReason {
+				t.Errorf("evaluate() = %v, %q, want %v, %q", on, reason, tt.wantOn, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestRolloutBucketIsStable(t *testing.T) {
+	bucket := rolloutBucket("flag", "42")
+
+	for range 10 {
+		if got := rolloutBucket("flag", "42"); got != bucket {
+			t.Fatalf("rolloutBucket() = %v, then %v", bucket, got)
+		}
+	}
+
+	if bucket < 0 || bucket >= 100 {
+		t.Errorf("rolloutBucket() = %v, want [0, 100)", bucket)
+	}
+}
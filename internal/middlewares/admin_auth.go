@@ -0,0 +1,28 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+)
+
+// AdminAuthMiddleware only lets through the requests with the admin token as bearer token
+type AdminAuthMiddleware struct {
+	token string
+}
+
+func NewAdminAuthMiddleware(token string) *AdminAuthMiddleware {
+	return &AdminAuthMiddleware{token}
+}
+
+func (am *AdminAuthMiddleware) HandleRequest(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || am.token == "" || subtle.ConstantTimeCompare([]byte(token), []byte(am.token)) != 1 {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
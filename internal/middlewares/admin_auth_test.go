@@ -0,0 +1,41 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminAuthMiddleware(t *testing.T) {
+	tests := []struct {
+		name          string
+		token         string
+		authorization string
+		wantStatus    int
+	}{
+		{"valid token", "s3cret", "Bearer s3cret", http.StatusOK},
+		{"missing header", "s3cret", "", http.StatusUnauthorized},
+		{"wrong token", "s3cret", "Bearer guess", http.StatusUnauthorized},
+		{"not a bearer token", "s3cret", "s3cret", http.StatusUnauthorized},
+		{"no configured token", "", "Bearer ", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+			handler := NewAdminAuthMiddleware(tt.token).HandleRequest(next)
+
+			req := httptest.NewRequest(http.MethodGet, "/admin/log-levels", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %v, want %v", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
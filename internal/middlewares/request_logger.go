@@ -2,14 +2,12 @@ package middlewares
 
 import (
 	"context"
-	"io"
+	"log/slog"
 	"net/http"
-	"time"
 
-	"github.com/bermr/api-golang-base/internal/config"
-	"github.com/bermr/api-golang-base/internal/tools/logger"
+	"github.com/bermr/api-golang-base/internal/featureflags"
+	"github.com/bermr/api-golang-base/internal/tools/clock"
 	"github.com/bermr/api-golang-base/internal/tools/my_logger"
-	"github.com/bermr/api-golang-base/internal/tools/util"
 	"github.com/google/uuid"
 )
 
@@ -19,8 +17,8 @@ type loggingResponseWriter struct {
 }
 
 type RequestLoggerMiddleware struct {
-	config             *config.Config
-	loggerOutputStream io.Writer
+	logger *my_logger.Logger
+	clock  clock.Clock
 }
 
 func NewLoggingResponseWriter(w http.ResponseWriter) *loggingResponseWriter {
@@ -32,32 +30,52 @@ func (lrw *loggingResponseWriter) WriteHeader(code int) {
 	lrw.ResponseWriter.WriteHeader(code)
 }
 
-func NewLoggerMiddleware(config *config.Config, loggerOutputStream io.Writer) *RequestLoggerMiddleware {
-	return &RequestLoggerMiddleware{config, loggerOutputStream}
+func NewLoggerMiddleware(logger *my_logger.Logger, clock clock.Clock) *RequestLoggerMiddleware {
+	return &RequestLoggerMiddleware{logger, clock}
 }
 
 func (lm RequestLoggerMiddleware) HandleRequest(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		reqStartedAt := time.Now()
+		reqStartedAt := lm.clock.Now()
 		lrw := NewLoggingResponseWriter(w)
-		log := logger.GetLogger(lm.config, lm.loggerOutputStream)
+		requestId := uuid.New().String()
+		evalCtx := featureflags.NewEvalContext(r, requestId)
 
-		log.AddLogContext("uuid", uuid.New().String())
+		requestAttrs := []slog.Attr{slog.String("uuid", requestId)}
+		if traceId := requestTraceId(r); traceId != "" {
+			requestAttrs = append(requestAttrs, slog.String("trace_id", traceId))
+		}
+		if evalCtx.UserId != "" {
+			requestAttrs = append(requestAttrs, slog.String("user_id", evalCtx.UserId))
+		}
+
+		log := lm.logger.With(my_logger.BuildAttrsFromSlice(requestAttrs)...)
 		log.Info("HTTP Request started", r)
 
-		loggerContext := context.WithValue(r.Context(), util.CtxKey("_reqLogger"), log)
+		// plain slog calls made with the request context get the request attrs too
+		loggerContext := my_logger.ContextWithAttrs(r.Context(), requestAttrs...)
+		loggerContext = my_logger.NewContext(loggerContext, log)
+		loggerContext = featureflags.NewContext(loggerContext, evalCtx)
 		context.AfterFunc(loggerContext, func() {
 			resLogData := &my_logger.HttpResponseLogData{
-				Time:       time.Since(reqStartedAt),
+				Time:       lm.clock.Since(reqStartedAt),
 				StatusCode: lrw.statusCode,
 				Path:       r.URL.Path,
 			}
 
 			log.Info("HTTP Request finished", resLogData)
-			log.ClearLogContext()
 		})
 
 		r = r.WithContext(loggerContext)
 		next.ServeHTTP(lrw, r)
 	})
 }
+
+// requestTraceId returns the trace id propagated by the load balancer or the caller, if any
+func requestTraceId(r *http.Request) string {
+	if traceId := r.Header.Get("X-Amzn-Trace-Id"); traceId != "" {
+		return traceId
+	}
+
+	return r.Header.Get("traceparent")
+}
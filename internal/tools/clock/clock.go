@@ -0,0 +1,151 @@
+package clock
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// Clock tells the time. Inject it instead of calling time.Now so the time can be faked in tests.
+type Clock interface {
+	Now() time.Time
+	Since(t time.Time) time.Duration
+	NewTicker(d time.Duration) Ticker
+}
+
+// Ticker delivers the time on C every tick, dropping the ticks of slow receivers like time.Ticker
+type Ticker interface {
+	C() <-chan time.Time
+	Stop()
+}
+
+type realClock struct {
+	location *time.Location
+}
+
+// New returns a clock reading the system time in location, or UTC if location is nil
+func New(location *time.Location) Clock {
+	if location == nil {
+		location = time.UTC
+	}
+
+	return &realClock{location}
+}
+
+func (r *realClock) Now() time.Time {
+	return time.Now().In(r.location)
+}
+
+func (r *realClock) Since(t time.Time) time.Duration {
+	return time.Since(t)
+}
+
+func (r *realClock) NewTicker(d time.Duration) Ticker {
+	return &realTicker{time.NewTicker(d)}
+}
+
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+func (r *realTicker) C() <-chan time.Time {
+	return r.ticker.C
+}
+
+func (r *realTicker) Stop() {
+	r.ticker.Stop()
+}
+
+// Fake is a clock that only moves when told to
+type Fake struct {
+	now     time.Time
+	tickers []*fakeTicker
+	mu      sync.Mutex
+}
+
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.now
+}
+
+func (f *Fake) Since(t time.Time) time.Duration {
+	return f.Now().Sub(t)
+}
+
+// NewTicker returns a ticker that ticks when the clock is moved past its next tick
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	ticker := &fakeTicker{
+		c:        make(chan time.Time, 1),
+		period:   d,
+		nextTick: f.now.Add(d),
+		clock:    f,
+	}
+	f.tickers = append(f.tickers, ticker)
+
+	return ticker
+}
+
+// Set moves the clock to now
+func (f *Fake) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = now
+	f.tick()
+}
+
+// Advance moves the clock forward by d
+func (f *Fake) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.now = f.now.Add(d)
+	f.tick()
+}
+
+// tick fires the tickers due by now. Must be called with the lock held.
+func (f *Fake) tick() {
+	for _, ticker := range f.tickers {
+		if ticker.nextTick.After(f.now) {
+			continue
+		}
+
+		select {
+		case ticker.c <- f.now:
+		default:
+		}
+
+		for !ticker.nextTick.After(f.now) {
+			ticker.nextTick = ticker.nextTick.Add(ticker.period)
+		}
+	}
+}
+
+type fakeTicker struct {
+	c        chan time.Time
+	period   time.Duration
+	nextTick time.Time
+	clock    *Fake
+}
+
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTicker) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+
+	t.clock.tickers = slices.DeleteFunc(t.clock.tickers, func(ticker *fakeTicker) bool {
+		return ticker == t
+	})
+}
@@ -0,0 +1,76 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeTicker(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		advances  []time.Duration
+		wantTicks int
+	}{
+		{"before the first tick", []time.Duration{999 * time.Millisecond}, 0},
+		{"on the tick", []time.Duration{time.Second}, 1},
+		{"each tick", []time.Duration{time.Second, time.Second, 1500 * time.Millisecond}, 3},
+		{"skipped ticks are dropped", []time.Duration{10 * time.Second}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := NewFake(start)
+			ticker := fake.NewTicker(time.Second)
+			defer ticker.Stop()
+
+			ticks := 0
+			for _, d := range tt.advances {
+				fake.Advance(d)
+
+				select {
+				case tick := <-ticker.C():
+					if !tick.Equal(fake.Now()) {
+						t.Errorf("tick = %v, want %v", tick, fake.Now())
+					}
+					ticks++
+				default:
+				}
+			}
+
+			if ticks != tt.wantTicks {
+				t.Errorf("ticked %v times, want %v", ticks, tt.wantTicks)
+			}
+		})
+	}
+}
+
+func TestFakeTickerStop(t *testing.T) {
+	fake := NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	ticker := fake.NewTicker(time.Second)
+
+	ticker.Stop()
+	fake.Advance(time.Minute)
+
+	select {
+	case <-ticker.C():
+		t.Errorf("stopped ticker ticked")
+	default:
+	}
+}
+
+func TestFakeSince(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake := NewFake(start)
+
+	fake.Advance(90 * time.Second)
+	if got := fake.Since(start); got != 90*time.Second {
+		t.Errorf("Since() = %v, want 90s", got)
+	}
+
+	fake.Set(start)
+	if got := fake.Since(start); got != 0 {
+		t.Errorf("Since() after Set = %v, want 0", got)
+	}
+}
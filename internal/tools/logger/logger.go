@@ -1,6 +1,7 @@
 package logger
 
 import (
+	"errors"
 	"io"
 	"log/slog"
 	"os"
@@ -9,12 +10,17 @@ import (
 	"github.com/bermr/api-golang-base/internal/tools/my_logger"
 )
 
-func GetLogger(cfg *config.Config, output io.Writer) *my_logger.Logger {
+func GetLogger(cfg *config.Config, outputs []my_logger.Output) *my_logger.Logger {
 	logger, err := my_logger.NewLogger(&my_logger.LoggerOptions{
-		AppName: cfg.AppName,
-		Version: "1.0.1",
-		Level:   cfg.LogLevel,
-		Output:  output,
+		AppName:      cfg.AppName,
+		Version:      "1.0.1",
+		Level:        cfg.LogLevel,
+		Format:       cfg.LogFormat,
+		Outputs:      outputs,
+		ModuleLevels: cfg.LogModuleLevels,
+		Location:     cfg.Location,
+		Sampling:     samplingOptions(cfg.LogSampling),
+		Dedup:        dedupOptions(cfg.LogDedup),
 	})
 
 	if err != nil {
@@ -25,11 +31,111 @@ func GetLogger(cfg *config.Config, output io.Writer) *my_logger.Logger {
 	return logger
 }
 
+func samplingOptions(logSampling *config.LogSampling) *my_logger.SamplingOptions {
+	if logSampling == nil {
+		return nil
+	}
+
+	return &my_logger.SamplingOptions{
+		Interval: logSampling.IntervalMs,
+		Default:  samplingRule(logSampling.Default),
+		Levels:   samplingRules(logSampling.Levels),
+		Messages: samplingRules(logSampling.Messages),
+	}
+}
+
+func samplingRules(rules map[string]*config.LogSamplingRule) map[string]*my_logger.SamplingRule {
+	if rules == nil {
+		return nil
+	}
+
+	samplingRules := make(map[string]*my_logger.SamplingRule, len(rules))
+	for key, rule := range rules {
+		samplingRules[key] = samplingRule(rule)
+	}
+
+	return samplingRules
+}
+
+func samplingRule(rule *config.LogSamplingRule) *my_logger.SamplingRule {
+	if rule == nil {
+		return nil
+	}
+
+	return &my_logger.SamplingRule{First: rule.First, Thereafter: rule.Thereafter}
+}
+
+func dedupOptions(logDedup *config.LogDedup) *my_logger.DedupOptions {
+	if logDedup == nil {
+		return nil
+	}
+
+	opts := &my_logger.DedupOptions{Window: logDedup.WindowMs}
+	if logDedup.Level != nil {
+		opts.Level = *logDedup.Level
+	}
+
+	return opts
+}
+
+// Outputs returns the sinks set by cfg.LogOutputs, or the OutputStream if there are none
+func Outputs(cfg *config.Config) []my_logger.Output {
+	var firehoseLogStream io.Writer
+	var logFile io.Writer
+
+	if len(cfg.LogOutputs) == 0 {
+		return []my_logger.Output{{Writer: OutputStream(cfg)}}
+	}
+
+	outputs := make([]my_logger.Output, 0, len(cfg.LogOutputs))
+
+	for _, logOutput := range cfg.LogOutputs {
+		output := my_logger.Output{Name: logOutput.Type}
+
+		switch logOutput.Type {
+		case "stdout":
+			output.Writer = os.Stdout
+		case "stderr":
+			output.Writer = os.Stderr
+		case "firehose":
+			// a single stream is shared by the firehose outputs
+			if firehoseLogStream == nil {
+				firehoseLogStream = newFirehoseLogStream(cfg)
+			}
+			output.Writer = firehoseLogStream
+		case "file":
+			if logFile == nil {
+				logFile = newLogFile(cfg)
+			}
+			output.Writer = logFile
+		}
+
+		if logOutput.Level != nil {
+			output.Level = *logOutput.Level
+		}
+		if logOutput.Format != nil {
+			output.Format = *logOutput.Format
+		}
+
+		outputs = append(outputs, output)
+	}
+
+	return outputs
+}
+
 func OutputStream(cfg *config.Config) io.Writer {
+	if cfg.LogFile != nil {
+		return newLogFile(cfg)
+	}
+
 	if cfg.Env == "development" {
 		return os.Stdout
 	}
 
+	return newFirehoseLogStream(cfg)
+}
+
+func newFirehoseLogStream(cfg *config.Config) io.Writer {
 	firehoseLogStream, err := my_logger.NewFirehoseLogStream(my_logger.FirehoseLogStreamOptions{
 		StreamName: cfg.AppName,
 	})
@@ -40,3 +146,34 @@ func OutputStream(cfg *config.Config) io.Writer {
 
 	return firehoseLogStream
 }
+
+func newLogFile(cfg *config.Config) io.Writer {
+	if cfg.LogFile == nil {
+		panic(errors.New("file log output without a log_file config"))
+	}
+
+	opts := my_logger.RotatingFileOptions{
+		Path:           cfg.LogFile.Path,
+		MaxBackups:     cfg.LogFile.MaxBackups,
+		Compress:       cfg.LogFile.Compress,
+		ReopenOnHangup: true,
+	}
+
+	if cfg.LogFile.MaxSizeMB != nil {
+		maxBytes := *cfg.LogFile.MaxSizeMB * 1024 * 1024
+		opts.MaxBytes = &maxBytes
+	}
+
+	if cfg.LogFile.RotateHours != nil {
+		rotateEvery := *cfg.LogFile.RotateHours * 60 * 60 * 1000
+		opts.RotateEvery = &rotateEvery
+	}
+
+	logFile, err := my_logger.NewRotatingFile(opts)
+	if err != nil {
+		slog.Info("log file creation error", "err", err)
+		panic(err)
+	}
+
+	return logFile
+}
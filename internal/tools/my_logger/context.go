@@ -0,0 +1,30 @@
+package my_logger
+
+import (
+	"context"
+	"os"
+	"sync"
+)
+
+type ctxKey struct{}
+
+// fallbackLogger is returned by FromContext for the contexts without a logger
+var fallbackLogger = sync.OnceValue(func() *Logger {
+	logger, _ := NewLogger(&LoggerOptions{Level: "info", Output: os.Stderr, Format: "text"})
+	return logger
+})
+
+// NewContext returns a copy of ctx carrying the logger l
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext returns the logger carried by ctx or, if there is none, a logger writing the
+// info and above records to stderr, as text
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(ctxKey{}).(*Logger); ok {
+		return l
+	}
+
+	return fallbackLogger()
+}
@@ -0,0 +1,68 @@
+package my_logger
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+)
+
+type ctxAttrsKey struct{}
+
+// ContextWithAttrs returns a copy of ctx carrying attrs, added by ContextHandler to every
+// record logged with it, e.g. the request id, trace id and user id
+func ContextWithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	ctxAttrs := slices.Concat(attrsFromContext(ctx), attrs)
+	return context.WithValue(ctx, ctxAttrsKey{}, ctxAttrs)
+}
+
+func attrsFromContext(ctx context.Context) []slog.Attr {
+	if ctx == nil {
+		return nil
+	}
+
+	attrs, _ := ctx.Value(ctxAttrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+// ContextHandler wraps a slog.Handler, adding the attrs carried by the record context, so
+// plain slog.InfoContext(ctx, ...) calls are correlated with the request. Attrs already
+// added with Logger.With are not repeated. Records of a logger with a group get the attrs
+// inside that group.
+type ContextHandler struct {
+	next     slog.Handler
+	withKeys map[string]bool
+}
+
+func NewContextHandler(next slog.Handler) *ContextHandler {
+	return &ContextHandler{next: next, withKeys: map[string]bool{}}
+}
+
+func (c *ContextHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return c.next.Enabled(ctx, level)
+}
+
+func (c *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	for _, attr := range attrsFromContext(ctx) {
+		if !c.withKeys[attr.Key] {
+			r.AddAttrs(attr)
+		}
+	}
+
+	return c.next.Handle(ctx, r)
+}
+
+func (c *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	withKeys := make(map[string]bool, len(c.withKeys)+len(attrs))
+	for key := range c.withKeys {
+		withKeys[key] = true
+	}
+	for _, attr := range attrs {
+		withKeys[attr.Key] = true
+	}
+
+	return &ContextHandler{next: c.next.WithAttrs(attrs), withKeys: withKeys}
+}
+
+func (c *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{next: c.next.WithGroup(name), withKeys: c.withKeys}
+}
@@ -0,0 +1,195 @@
+package my_logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default time repeated records are collapsed over
+const default_dedup_ms_window = 10 * 1000
+
+// DedupOptions collapses repeated records: the first one is logged, the repeats in the
+// window are dropped and counted, and at the end of the window the first record is logged
+// again with the count as the repeated attr. Records repeat when they have the same level,
+// message and error attrs, the other attrs (e.g. the request uuid) may differ.
+type DedupOptions struct {
+	// Time repeated records are collapsed over, in ms
+	Window *int
+
+	// Minimum level deduplicated, defaults to error
+	Level string
+}
+
+type dedupEntry struct {
+	ctx      context.Context
+	handler  slog.Handler
+	record   slog.Record
+	repeated int
+	timer    *time.Timer
+}
+
+// deduper holds the records of the current windows of a logger, shared by all its copies
+type deduper struct {
+	level  slog.Level
+	window time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*dedupEntry
+}
+
+func newDeduper(opts *DedupOptions) (*deduper, error) {
+	d := &deduper{
+		level:   slog.LevelError,
+		window:  time.Millisecond * default_dedup_ms_window,
+		entries: map[string]*dedupEntry{},
+	}
+
+	if opts.Window != nil {
+		if *opts.Window <= 0 {
+			return nil, errors.New("dedup window must be positive")
+		}
+		d.window = time.Millisecond * time.Duration(*opts.Window)
+	}
+
+	if opts.Level != "" {
+		level, err := ParseLevel(opts.Level)
+		if err != nil {
+			return nil, fmt.Errorf("dedup: %w", err)
+		}
+		d.level = level.Level()
+	}
+
+	return d, nil
+}
+
+// first reports whether r is the first of its window, to be logged. The repeats are
+// counted and logged by handler at the end of the window.
+func (d *deduper) first(ctx context.Context, handler slog.Handler, r slog.Record) bool {
+	if r.Level < d.level {
+		return true
+	}
+
+	key := dedupKey(r)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if entry, ok := d.entries[key]; ok {
+		entry.repeated++
+		return false
+	}
+
+	entry := &dedupEntry{ctx: ctx, handler: handler, record: r.Clone()}
+	entry.timer = time.AfterFunc(d.window, func() {
+		d.expire(key, entry)
+	})
+	d.entries[key] = entry
+
+	return true
+}
+
+func (d *deduper) expire(key string, entry *dedupEntry) {
+	d.mu.Lock()
+	if d.entries[key] != entry {
+		d.mu.Unlock()
+		return
+	}
+	delete(d.entries, key)
+	d.mu.Unlock()
+
+	d.emit(entry)
+}
+
+// flush ends every window, logging the pending repeats, e.g. before exiting
+func (d *deduper) flush() error {
+	d.mu.Lock()
+	entries := d.entries
+	d.entries = map[string]*dedupEntry{}
+	d.mu.Unlock()
+
+	for _, entry := range entries {
+		entry.timer.Stop()
+		d.emit(entry)
+	}
+
+	return nil
+}
+
+func (d *deduper) emit(entry *dedupEntry) {
+	if entry.repeated == 0 {
+		return
+	}
+
+	r := entry.record.Clone()
+	r.Time = time.Now()
+	r.AddAttrs(slog.Int("repeated", entry.repeated))
+
+	entry.handler.Handle(entry.ctx, r)
+}
+
+// dedupKey identifies r by its level, message and error attrs: error values and the
+// groups serialized from errors, compared by their msg and type
+func dedupKey(r slog.Record) string {
+	var key strings.Builder
+
+	fmt.Fprintf(&key, "%v|%v", r.Level, r.Message)
+
+	r.Attrs(func(a slog.Attr) bool {
+		switch a.Value.Kind() {
+		case slog.KindAny:
+			if err, ok := a.Value.Any().(error); ok {
+				fmt.Fprintf(&key, "|%v=%v", a.Key, err.Error())
+			}
+
+		case slog.KindGroup:
+			var msg, errType string
+			for _, groupAttr := range a.Value.Group() {
+				switch groupAttr.Key {
+				case "msg":
+					msg = groupAttr.Value.String()
+				case "type":
+					errType = groupAttr.Value.String()
+				}
+			}
+
+			if msg != "" && errType != "" {
+				fmt.Fprintf(&key, "|%v=%v:%v", a.Key, errType, msg)
+			}
+		}
+
+		return true
+	})
+
+	return key.String()
+}
+
+// dedupHandler drops the repeated records, see DedupOptions
+type dedupHandler struct {
+	next    slog.Handler
+	deduper *deduper
+}
+
+func (h *dedupHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *dedupHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.deduper.first(ctx, h.next, r) {
+		return nil
+	}
+
+	return h.next.Handle(ctx, r)
+}
+
+func (h *dedupHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &dedupHandler{next: h.next.WithAttrs(attrs), deduper: h.deduper}
+}
+
+func (h *dedupHandler) WithGroup(name string) slog.Handler {
+	return &dedupHandler{next: h.next.WithGroup(name), deduper: h.deduper}
+}
@@ -0,0 +1,137 @@
+package my_logger
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+)
+
+// Max depth of the error chain walked when serializing an error
+const max_error_chain_depth = 16
+
+// ErrorWithAttrs is implemented by errors carrying structured fields to be logged with them
+type ErrorWithAttrs interface {
+	error
+	LogAttrs() []slog.Attr
+}
+
+type stackTracer interface {
+	Stack() []byte
+}
+
+type stackError struct {
+	err   error
+	stack []byte
+}
+
+// WithStack wraps err with the stack trace of the caller, logged when err is serialized
+func WithStack(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return &stackError{err, debug.Stack()}
+}
+
+func (s *stackError) Error() string {
+	return s.err.Error()
+}
+
+func (s *stackError) Unwrap() error {
+	return s.err
+}
+
+func (s *stackError) Stack() []byte {
+	return s.stack
+}
+
+// PanicError is a value recovered from a panic, with the stack trace of the panic
+type PanicError struct {
+	Value any
+	stack []byte
+}
+
+// NewPanicError wraps a recover() value, capturing the stack trace. Must be called in the
+// deferred function that recovered, so the stack still includes the panicking frames.
+func NewPanicError(recovered any) *PanicError {
+	return &PanicError{recovered, debug.Stack()}
+}
+
+func (p *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", p.Value)
+}
+
+// Unwrap returns the panic value if it is an error
+func (p *PanicError) Unwrap() error {
+	err, _ := p.Value.(error)
+	return err
+}
+
+func (p *PanicError) Stack() []byte {
+	return p.stack
+}
+
+// serializeError logs the error message and type, the messages and types of the wrapped
+// and joined errors, the first stack trace found in the chain and the attrs of every error
+// in the chain implementing ErrorWithAttrs
+func serializeError(e error) slog.Attr {
+	var chain []map[string]string
+	var stack []byte
+	var errAttrs []slog.Attr
+
+	visitErrorChain(e, 0, func(err error, depth int) {
+		if depth > 0 {
+			chain = append(chain, map[string]string{
+				"msg":  err.Error(),
+				"type": fmt.Sprintf("%T", err),
+			})
+		}
+
+		if tracer, ok := err.(stackTracer); ok && stack == nil {
+			stack = tracer.Stack()
+		}
+
+		if withAttrs, ok := err.(ErrorWithAttrs); ok {
+			errAttrs = append(errAttrs, withAttrs.LogAttrs()...)
+		}
+	})
+
+	attrs := []any{
+		slog.String("msg", e.Error()),
+		slog.String("type", fmt.Sprintf("%T", e)),
+	}
+
+	if len(chain) > 0 {
+		attrs = append(attrs, slog.Any("chain", chain))
+	}
+
+	if stack != nil {
+		attrs = append(attrs, slog.String("stack", string(stack)))
+	}
+
+	if len(errAttrs) > 0 {
+		attrs = append(attrs, slog.Attr{Key: "attrs", Value: slog.GroupValue(errAttrs...)})
+	}
+
+	return slog.Group("err", attrs...)
+}
+
+// visitErrorChain calls visit for err and every error it wraps, following both
+// errors.Unwrap and errors.Join
+func visitErrorChain(err error, depth int, visit func(err error, depth int)) {
+	if err == nil || depth > max_error_chain_depth {
+		return
+	}
+
+	visit(err, depth)
+
+	switch wrapper := err.(type) {
+	case interface{ Unwrap() []error }:
+		for _, wrapped := range wrapper.Unwrap() {
+			visitErrorChain(wrapped, depth+1, visit)
+		}
+	default:
+		visitErrorChain(errors.Unwrap(err), depth+1, visit)
+	}
+}
@@ -0,0 +1,157 @@
+package my_logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+type testAttrsError struct {
+	userID int
+}
+
+func (e *testAttrsError) Error() string {
+	return "user not allowed"
+}
+
+func (e *testAttrsError) LogAttrs() []slog.Attr {
+	return []slog.Attr{slog.Int("user_id", e.userID)}
+}
+
+// loggedError logs err and returns its serialized err group
+func loggedError(t *testing.T, err error) map[string]any {
+	t.Helper()
+
+	var output bytes.Buffer
+
+	logger, loggerErr := NewLogger(&LoggerOptions{Level: "info", Output: &output})
+	if loggerErr != nil {
+		t.Fatalf("NewLogger() error = %v", loggerErr)
+	}
+
+	logger.Error("failed", err)
+
+	group, ok := loggedRecords(t, &output)[0]["err"].(map[string]any)
+	if !ok {
+		t.Fatalf("no err group logged: %v", output.String())
+	}
+
+	return group
+}
+
+// chainMessages returns the messages of the logged error chain
+func chainMessages(group map[string]any) []string {
+	chain, _ := group["chain"].([]any)
+
+	messages := make([]string, 0, len(chain))
+	for _, link := range chain {
+		messages = append(messages, link.(map[string]any)["msg"].(string))
+	}
+
+	return messages
+}
+
+func recoveredPanic(value any) (panicErr *PanicError) {
+	defer func() {
+		panicErr = NewPanicError(recover())
+	}()
+
+	panickingFunction(value)
+
+	return nil
+}
+
+func panickingFunction(value any) {
+	panic(value)
+}
+
+func TestSerializeErrorChain(t *testing.T) {
+	notFound := errors.New("not found")
+	timeout := errors.New("timeout")
+
+	tests := []struct {
+		name      string
+		err       error
+		wantType  string
+		wantChain []string
+	}{
+		{"plain error", notFound, "*errors.errorString", []string{}},
+		{"wrapped", fmt.Errorf("loading user: %w", notFound), "*fmt.wrapError", []string{"not found"}},
+		{
+			"joined and wrapped",
+			errors.Join(fmt.Errorf("cache: %w", timeout), notFound),
+			"*errors.joinError",
+			[]string{"cache: timeout", "timeout", "not found"},
+		},
+		{
+			"panic wrapping an error",
+			recoveredPanic(fmt.Errorf("handler: %w", notFound)),
+			"*my_logger.PanicError",
+			[]string{"handler: not found", "not found"},
+		},
+		{"panic with a non error value", recoveredPanic("index out of range"), "*my_logger.PanicError", []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group := loggedError(t, tt.err)
+
+			if group["msg"] != tt.err.Error() || group["type"] != tt.wantType {
+				t.Errorf("err = %v (%v), want %v (%v)", group["msg"], group["type"], tt.err.Error(), tt.wantType)
+			}
+
+			if got := chainMessages(group); strings.Join(got, "|") != strings.Join(tt.wantChain, "|") {
+				t.Errorf("chain = %q, want %q", got, tt.wantChain)
+			}
+		})
+	}
+}
+
+func TestSerializeErrorStack(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantFrame string
+	}{
+		{"WithStack", fmt.Errorf("request: %w", WithStack(errors.New("boom"))), "TestSerializeErrorStack"},
+		{"panic", recoveredPanic("boom"), "panickingFunction"},
+		{"no stack", errors.New("boom"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stack, ok := loggedError(t, tt.err)["stack"].(string)
+
+			if tt.wantFrame == "" {
+				if ok {
+					t.Errorf("stack logged for an error without one: %v", stack)
+				}
+				return
+			}
+
+			if !strings.Contains(stack, tt.wantFrame) {
+				t.Errorf("stack = %v, want it to include %v", stack, tt.wantFrame)
+			}
+		})
+	}
+
+	if WithStack(nil) != nil {
+		t.Errorf("WithStack(nil) != nil")
+	}
+}
+
+func TestSerializeErrorWithAttrs(t *testing.T) {
+	err := fmt.Errorf("checkout: %w", errors.Join(&testAttrsError{userID: 42}, errors.New("audit failed")))
+
+	attrs, ok := loggedError(t, err)["attrs"].(map[string]any)
+	if !ok {
+		t.Fatalf("no attrs logged for a wrapped ErrorWithAttrs")
+	}
+
+	if attrs["user_id"] != float64(42) {
+		t.Errorf("attrs.user_id = %v, want 42", attrs["user_id"])
+	}
+}
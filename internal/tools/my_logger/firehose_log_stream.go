@@ -3,6 +3,7 @@ package my_logger
 import (
 	"context"
 	"fmt"
+	"os"
 	"slices"
 	"sync"
 	"time"
@@ -22,6 +23,9 @@ const (
 	// Customizable via options
 	max_record_batch_size    = 500
 	default_watcher_ms_delay = 1000
+
+	// Sends in a row without progress after which Close gives up on the buffered records
+	max_close_send_attempts = 3
 )
 
 type FirehoseLogStreamOptions struct {
@@ -36,18 +40,36 @@ type FirehoseLogStreamOptions struct {
 
 	// Instead of sending records trough the AWS API, print them to stdout
 	Debug bool
+
+	// Context bounding the AWS config load, defaults to context.Background()
+	Context context.Context
+
+	// Client used to call the Firehose API, e.g. a mock. Built from AWSConfig if nil.
+	Client FirehoseClient
+
+	// AWS config used to build the client, defaults to the shared AWS config
+	AWSConfig *aws.Config
+
+	// Custom Firehose API endpoint, e.g. a LocalStack URL
+	Endpoint string
 }
 
 type FirehoseLogStream struct {
 	options        FirehoseLogStreamOptions
 	recordsBuff    []types.Record
-	firehoseClient firehoseClient
+	firehoseClient FirehoseClient
 	ticker         *time.Ticker
 	mu             sync.Mutex
+
+	// writes not buffered yet. Only added to before closed is set, under closeMu, which
+	// is apart from mu so writes don't wait for the sends.
+	pending sync.WaitGroup
+	closeMu sync.RWMutex
+	closed  bool
 }
 
 // Interface to allow mocking of the AWS Firehose API
-type firehoseClient interface {
+type FirehoseClient interface {
 	PutRecordBatch(ctx context.Context, input *firehose.PutRecordBatchInput, optFns ...func(*firehose.Options)) (*firehose.PutRecordBatchOutput, error)
 }
 
@@ -66,7 +88,8 @@ func (f *firehoseDebugClient) PutRecordBatch(ctx context.Context, input *firehos
 func NewFirehoseLogStream(opts FirehoseLogStreamOptions) (*FirehoseLogStream, error) {
 	var watcherDelay int
 	var cfg aws.Config
-	var firehoseClient firehoseClient
+	var firehoseClient FirehoseClient
+	var err error
 
 	if opts.WatcherDelay == nil {
 		watcherDelay = default_watcher_ms_delay
@@ -79,14 +102,30 @@ func NewFirehoseLogStream(opts FirehoseLogStreamOptions) (*FirehoseLogStream, er
 		opts.MaxBatchSize = &defaultMaxBatchSize
 	}
 
-	cfg, err := config.LoadDefaultConfig(context.TODO())
-	if err != nil {
-		return nil, err
+	if opts.Context == nil {
+		opts.Context = context.Background()
 	}
 
-	firehoseClient = firehose.NewFromConfig(cfg)
-	if opts.Debug {
+	if opts.AWSConfig != nil {
+		cfg = *opts.AWSConfig
+	} else if opts.Client == nil && !opts.Debug {
+		cfg, err = config.LoadDefaultConfig(opts.Context)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	switch {
+	case opts.Debug:
 		firehoseClient = &firehoseDebugClient{cfg}
+	case opts.Client != nil:
+		firehoseClient = opts.Client
+	default:
+		firehoseClient = firehose.NewFromConfig(cfg, func(o *firehose.Options) {
+			if opts.Endpoint != "" {
+				o.BaseEndpoint = aws.String(opts.Endpoint)
+			}
+		})
 	}
 
 	firehoseStream := &FirehoseLogStream{
@@ -105,13 +144,25 @@ func NewFirehoseLogStream(opts FirehoseLogStreamOptions) (*FirehoseLogStream, er
 	return firehoseStream, nil
 }
 
+// Write buffers the record, to be sent by the next flush. It fails with os.ErrClosed after
+// Close.
 func (f *FirehoseLogStream) Write(logBytes []byte) (n int, err error) {
 	if len(logBytes) > max_log_byte_length {
 		fmt.Printf("log length exceeds %v B.\n", max_log_byte_length)
 		return len(logBytes), nil
 	}
 
+	f.closeMu.RLock()
+	defer f.closeMu.RUnlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+
+	f.pending.Add(1)
 	go func(r types.Record) {
+		defer f.pending.Done()
+
 		f.mu.Lock()
 		defer f.mu.Unlock()
 
@@ -125,19 +176,45 @@ func (f *FirehoseLogStream) Write(logBytes []byte) (n int, err error) {
 	return len(logBytes), nil
 }
 
+// Close stops the automatic flushes and sends the buffered records, giving up after
+// max_close_send_attempts sends in a row fail. It can be called more than once.
 func (f *FirehoseLogStream) Close() error {
-	f.mu.Lock()
-	defer f.mu.Unlock()
+	var failedAttempts int
+
+	f.closeMu.Lock()
+	alreadyClosed := f.closed
+	f.closed = true
+	f.closeMu.Unlock()
+
+	if alreadyClosed {
+		return nil
+	}
 
 	f.ticker.Stop()
+	f.pending.Wait()
+
+	for buffered := f.buffered(); buffered > 0; buffered = f.buffered() {
+		if f.send() > 0 {
+			failedAttempts = 0
+			continue
+		}
 
-	for len(f.recordsBuff) > 0 {
-		f.send()
+		failedAttempts++
+		if failedAttempts >= max_close_send_attempts {
+			return fmt.Errorf("%v log records not sent to firehose", buffered)
+		}
 	}
 
 	return nil
 }
 
+func (f *FirehoseLogStream) buffered() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.recordsBuff)
+}
+
 func (f *FirehoseLogStream) send() int {
 	f.mu.Lock()
 	defer f.mu.Unlock()
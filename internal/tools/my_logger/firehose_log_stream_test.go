@@ -0,0 +1,78 @@
+package my_logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/firehose"
+)
+
+// fakeFirehoseClient counts the records sent
+type fakeFirehoseClient struct {
+	sent atomic.Int64
+}
+
+func (f *fakeFirehoseClient) PutRecordBatch(_ context.Context, input *firehose.PutRecordBatchInput, _ ...func(*firehose.Options)) (*firehose.PutRecordBatchOutput, error) {
+	f.sent.Add(int64(len(input.Records)))
+
+	return &firehose.PutRecordBatchOutput{FailedPutCount: aws.Int32(0)}, nil
+}
+
+func TestFirehoseLogStreamWritesDuringClose(t *testing.T) {
+	client := &fakeFirehoseClient{}
+	watcherDelay, maxBatchSize := 60*60*1000, 7
+
+	stream, err := NewFirehoseLogStream(FirehoseLogStreamOptions{
+		StreamName:   "test",
+		Client:       client,
+		WatcherDelay: &watcherDelay,
+		MaxBatchSize: &maxBatchSize,
+	})
+	if err != nil {
+		t.Fatalf("NewFirehoseLogStream() error = %v", err)
+	}
+
+	var written atomic.Int64
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; ; j++ {
+				_, err := stream.Write([]byte(fmt.Sprintf("record %v-%v\n", i, j)))
+				if err == os.ErrClosed {
+					return
+				}
+				if err != nil {
+					t.Errorf("Write() error = %v", err)
+					return
+				}
+				written.Add(1)
+			}
+		}()
+	}
+
+	time.Sleep(20 * time.Millisecond)
+
+	err = stream.Close()
+	if err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	wg.Wait()
+
+	// every accepted write is sent, none is left in the buffer
+	if sent := client.sent.Load(); sent != written.Load() {
+		t.Errorf("sent %v records, %v written", sent, written.Load())
+	}
+
+	if err := stream.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+}
@@ -0,0 +1,337 @@
+package my_logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is a log level: the slog levels plus trace, critical and fatal
+type Level slog.Level
+
+const (
+	LevelTrace    = Level(-8)
+	LevelDebug    = Level(slog.LevelDebug)
+	LevelInfo     = Level(slog.LevelInfo)
+	LevelWarn     = Level(slog.LevelWarn)
+	LevelError    = Level(slog.LevelError)
+	LevelCritical = Level(12)
+	LevelFatal    = Level(16)
+)
+
+var levelNames = map[Level]string{
+	LevelTrace:    "trace",
+	LevelDebug:    "debug",
+	LevelInfo:     "info",
+	LevelWarn:     "warn",
+	LevelError:    "error",
+	LevelCritical: "critical",
+	LevelFatal:    "fatal",
+}
+
+// ParseLevel parses a level name, case insensitive
+func ParseLevel(name string) (Level, error) {
+	normalized := strings.ToLower(strings.TrimSpace(name))
+
+	for level, levelName := range levelNames {
+		if levelName == normalized {
+			return level, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown level %q", name)
+}
+
+// String returns the upper case level name, as printed in the records
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return strings.ToUpper(name)
+	}
+
+	return slog.Level(l).String()
+}
+
+func (l Level) Level() slog.Level {
+	return slog.Level(l)
+}
+
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+func (l *Level) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+
+	*l = level
+
+	return nil
+}
+
+type moduleLevel struct {
+	configured *Level
+
+	// temporary level, reverted when it expires
+	temporary  *Level
+	until      time.Time
+	timer      *time.Timer
+	generation uint64
+}
+
+// Levels holds the minimum level of a logger and of its modules, see Logger.Module. The
+// base level is the "" module. A module without its own level follows its parent, e.g.
+// "http/admin" follows "http", then the base level. Each level can be changed temporarily,
+// reverting to the configured one when the duration is over.
+type Levels struct {
+	base *slog.LevelVar
+
+	mu         sync.RWMutex
+	modules    map[string]*moduleLevel
+	generation uint64
+}
+
+// LevelState is the effective level of a module, and until when it is temporary
+type LevelState struct {
+	Module     string     `json:"module"`
+	Level      Level      `json:"level"`
+	Configured *Level     `json:"configured,omitempty"`
+	Until      *time.Time `json:"until,omitempty"`
+}
+
+func NewLevels(base Level) *Levels {
+	levels := &Levels{
+		base:    new(slog.LevelVar),
+		modules: map[string]*moduleLevel{"": {configured: &base}},
+	}
+	levels.base.Set(base.Level())
+
+	return levels
+}
+
+// Leveler returns the slog.Leveler of module, following its changes
+func (l *Levels) Leveler(module string) slog.Leveler {
+	if module == "" {
+		return l.base
+	}
+
+	return moduleLeveler{l, module}
+}
+
+// Level returns the effective level of module
+func (l *Levels) Level(module string) Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	return l.effective(module)
+}
+
+// Set sets the configured level of module
+func (l *Levels) Set(module string, level Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.state(module).configured = &level
+	l.update()
+}
+
+// SetModules replaces the configured levels of every module but the base one. Temporary
+// levels are kept.
+func (l *Levels) SetModules(levels map[string]Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for module, state := range l.modules {
+		if module != "" {
+			state.configured = nil
+		}
+	}
+
+	for module, level := range levels {
+		l.state(module).configured = &level
+	}
+
+	l.update()
+}
+
+// SetTemporary sets the level of module for duration, then reverts to the configured one
+func (l *Levels) SetTemporary(module string, level Level, duration time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state := l.state(module)
+	if state.timer != nil {
+		state.timer.Stop()
+	}
+
+	l.generation++
+	generation := l.generation
+
+	state.temporary = &level
+	state.until = time.Now().Add(duration)
+	state.generation = generation
+	state.timer = time.AfterFunc(duration, func() {
+		l.expire(module, generation)
+	})
+
+	l.update()
+}
+
+// Revert drops the temporary level of module, if any
+func (l *Levels) Revert(module string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state, ok := l.modules[module]
+	if !ok || state.temporary == nil {
+		return
+	}
+
+	state.timer.Stop()
+	state.temporary = nil
+	state.timer = nil
+
+	l.update()
+}
+
+// States lists the base level and every module with its own level, sorted by module
+func (l *Levels) States() []LevelState {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	states := make([]LevelState, 0, len(l.modules))
+
+	for _, module := range slices.Sorted(maps.Keys(l.modules)) {
+		state := l.modules[module]
+		levelState := LevelState{
+			Module:     module,
+			Level:      l.effective(module),
+			Configured: state.configured,
+		}
+
+		if state.temporary != nil {
+			until := state.until
+			levelState.Until = &until
+		}
+
+		states = append(states, levelState)
+	}
+
+	return states
+}
+
+func (l *Levels) expire(module string, generation uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state, ok := l.modules[module]
+	if !ok || state.generation != generation || state.temporary == nil {
+		return
+	}
+
+	state.temporary = nil
+	state.timer = nil
+
+	l.update()
+}
+
+// state returns the level of module, adding it if missing. Must hold the write lock.
+func (l *Levels) state(module string) *moduleLevel {
+	state, ok := l.modules[module]
+	if !ok {
+		state = &moduleLevel{}
+		l.modules[module] = state
+	}
+
+	return state
+}
+
+// update syncs the base LevelVar and drops the modules left without a level. Must hold
+// the write lock.
+func (l *Levels) update() {
+	for module, state := range l.modules {
+		if module != "" && state.configured == nil && state.temporary == nil {
+			delete(l.modules, module)
+		}
+	}
+
+	l.base.Set(l.effective("").Level())
+}
+
+// effective walks up from module to the base level. Must hold the lock.
+func (l *Levels) effective(module string) Level {
+	for {
+		if state, ok := l.modules[module]; ok {
+			if state.temporary != nil {
+				return *state.temporary
+			}
+			if state.configured != nil {
+				return *state.configured
+			}
+		}
+
+		if module == "" {
+			return LevelInfo
+		}
+
+		module = module[:max(strings.LastIndex(module, "/"), 0)]
+	}
+}
+
+type moduleLeveler struct {
+	levels *Levels
+	module string
+}
+
+func (m moduleLeveler) Level() slog.Level {
+	return m.levels.Level(m.module).Level()
+}
+
+// levelHandler drops the records below the level of its leveler. The wrapped handlers
+// accept every level, so a module can log below the base level.
+type levelHandler struct {
+	next    slog.Handler
+	leveler slog.Leveler
+}
+
+func (h *levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.leveler.Level() && h.next.Enabled(ctx, level)
+}
+
+func (h *levelHandler) Handle(ctx context.Context, r slog.Record) error {
+	return h.next.Handle(ctx, r)
+}
+
+func (h *levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &levelHandler{next: h.next.WithAttrs(attrs), leveler: h.leveler}
+}
+
+func (h *levelHandler) WithGroup(name string) slog.Handler {
+	return &levelHandler{next: h.next.WithGroup(name), leveler: h.leveler}
+}
+
+func parseModuleLevels(moduleLevels map[string]string) (map[string]Level, error) {
+	levels := make(map[string]Level, len(moduleLevels))
+
+	for module, name := range moduleLevels {
+		if module == "" {
+			return nil, errors.New("module level with an empty module name")
+		}
+
+		level, err := ParseLevel(name)
+		if err != nil {
+			return nil, fmt.Errorf("module %v: %w", module, err)
+		}
+
+		levels[module] = level
+	}
+
+	return levels, nil
+}
@@ -0,0 +1,108 @@
+package my_logger
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Level
+		wantErr bool
+	}{
+		{"trace", LevelTrace, false},
+		{" Critical ", LevelCritical, false},
+		{"FATAL", LevelFatal, false},
+		{"", 0, true},
+		{"loud", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseLevel(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseLevel() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseLevel() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevelsModuleLookup(t *testing.T) {
+	levels := NewLevels(LevelWarn)
+	levels.SetModules(map[string]Level{
+		"http":        LevelDebug,
+		"http/admin":  LevelError,
+		"jobs/emails": LevelTrace,
+	})
+
+	tests := []struct {
+		module string
+		want   Level
+	}{
+		{"", LevelWarn},
+		{"http", LevelDebug},
+		{"http/admin", LevelError},
+		{"http/admin/audit", LevelError},
+		{"http/public", LevelDebug},
+		{"jobs", LevelWarn},
+		{"jobs/emails", LevelTrace},
+		{"httpx", LevelWarn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.module, func(t *testing.T) {
+			if got := levels.Level(tt.module); got != tt.want {
+				t.Errorf("Level(%q) = %v, want %v", tt.module, got, tt.want)
+			}
+			if got := levels.Leveler(tt.module).Level(); got != tt.want.Level() {
+				t.Errorf("Leveler(%q).Level() = %v, want %v", tt.module, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLevelsTemporary(t *testing.T) {
+	levels := NewLevels(LevelInfo)
+	levels.Set("http", LevelWarn)
+
+	levels.SetTemporary("http", LevelDebug, 20*time.Millisecond)
+	if got := levels.Level("http"); got != LevelDebug {
+		t.Fatalf("Level(http) = %v, want the temporary DEBUG", got)
+	}
+
+	// a module reload keeps the temporary level
+	levels.SetModules(map[string]Level{"http": LevelError})
+	if got := levels.Level("http"); got != LevelDebug {
+		t.Errorf("Level(http) = %v after SetModules, want the temporary DEBUG", got)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for levels.Level("http") != LevelError && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := levels.Level("http"); got != LevelError {
+		t.Errorf("Level(http) = %v after expiry, want the configured ERROR", got)
+	}
+
+	levels.SetTemporary("", LevelTrace, time.Hour)
+	levels.Revert("")
+	if got := levels.Leveler("").Level(); got != LevelInfo.Level() {
+		t.Errorf("base level = %v after revert, want INFO", got)
+	}
+}
+
+func TestLevelsDropModulesWithoutLevel(t *testing.T) {
+	levels := NewLevels(LevelInfo)
+	levels.SetTemporary("http", LevelDebug, time.Hour)
+	levels.Revert("http")
+
+	for _, state := range levels.States() {
+		if state.Module == "http" {
+			t.Errorf("reverted module without a configured level still listed")
+		}
+	}
+}
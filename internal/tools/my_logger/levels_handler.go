@@ -0,0 +1,72 @@
+package my_logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// Default duration of a level changed through the levels handler
+const default_level_ms_revert = 10 * 60 * 1000
+
+// LevelChange is the body of a levels handler PUT
+type LevelChange struct {
+	// Module to change, "" for the base level
+	Module string `json:"module"`
+
+	// Required, one of the level names
+	Level *Level `json:"level"`
+
+	// How long the level lasts before reverting, e.g. "30m", 10 minutes if empty
+	Duration string `json:"duration"`
+}
+
+// NewLevelsHandler returns an admin handler for levels:
+//   - GET lists the level states
+//   - PUT changes a level temporarily, see LevelChange
+//   - DELETE reverts the level of the module query param
+//
+// Every method responds with the level states. The handler has no authentication of its own.
+func NewLevelsHandler(levels *Levels) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+
+		case http.MethodPut:
+			var change LevelChange
+
+			err := json.NewDecoder(r.Body).Decode(&change)
+			if err != nil {
+				http.Error(w, "invalid level change: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+
+			if change.Level == nil {
+				http.Error(w, "invalid level change: level is required", http.StatusBadRequest)
+				return
+			}
+
+			duration := time.Duration(default_level_ms_revert) * time.Millisecond
+			if change.Duration != "" {
+				duration, err = time.ParseDuration(change.Duration)
+				if err != nil || duration <= 0 {
+					http.Error(w, "invalid level change duration", http.StatusBadRequest)
+					return
+				}
+			}
+
+			levels.SetTemporary(change.Module, *change.Level, duration)
+
+		case http.MethodDelete:
+			levels.Revert(r.URL.Query().Get("module"))
+
+		default:
+			w.Header().Set("Allow", "GET, PUT, DELETE")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(levels.States())
+	})
+}
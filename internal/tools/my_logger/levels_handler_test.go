@@ -0,0 +1,95 @@
+package my_logger
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLevelsHandlerPut(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantModule string
+		wantLevel  Level
+		wantUntil  time.Duration
+	}{
+		{"invalid json", `{"level": `, http.StatusBadRequest, "", LevelInfo, 0},
+		{"missing level", `{"module": "http"}`, http.StatusBadRequest, "http", LevelInfo, 0},
+		{"empty level", `{"level": ""}`, http.StatusBadRequest, "", LevelInfo, 0},
+		{"unknown level", `{"level": "loud"}`, http.StatusBadRequest, "", LevelInfo, 0},
+		{"invalid duration", `{"level": "debug", "duration": "-1m"}`, http.StatusBadRequest, "", LevelInfo, 0},
+		{"base level with the default duration", `{"level": "debug"}`, http.StatusOK, "", LevelDebug, 10 * time.Minute},
+		{"module level with a duration", `{"module": "http", "level": "warn", "duration": "30m"}`, http.StatusOK, "http", LevelWarn, 30 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			levels := NewLevels(LevelInfo)
+			handler := NewLevelsHandler(levels)
+
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/admin/log-levels", strings.NewReader(tt.body)))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %v, want %v: %v", rec.Code, tt.wantStatus, rec.Body)
+			}
+
+			if got := levels.Level(tt.wantModule); got != tt.wantLevel {
+				t.Errorf("Level(%q) = %v, want %v", tt.wantModule, got, tt.wantLevel)
+			}
+
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var states []LevelState
+			err := json.NewDecoder(rec.Body).Decode(&states)
+			if err != nil {
+				t.Fatalf("decoding the level states: %v", err)
+			}
+
+			for _, state := range states {
+				if state.Module != tt.wantModule {
+					continue
+				}
+
+				if state.Until == nil {
+					t.Fatalf("module %q has no expiry", tt.wantModule)
+				}
+				if remaining := time.Until(*state.Until); remaining > tt.wantUntil || remaining < tt.wantUntil-time.Minute {
+					t.Errorf("module %q expires in %v, want %v", tt.wantModule, remaining, tt.wantUntil)
+				}
+			}
+		})
+	}
+}
+
+func TestLevelsHandlerDelete(t *testing.T) {
+	levels := NewLevels(LevelInfo)
+	levels.SetTemporary("http", LevelDebug, time.Hour)
+	handler := NewLevelsHandler(levels)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/admin/log-levels?module=http", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %v, want 200", rec.Code)
+	}
+	if got := levels.Level("http"); got != LevelInfo {
+		t.Errorf("Level(http) = %v after revert, want INFO", got)
+	}
+}
+
+func TestLevelsHandlerMethodNotAllowed(t *testing.T) {
+	rec := httptest.NewRecorder()
+	NewLevelsHandler(NewLevels(LevelInfo)).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/log-levels", nil))
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("status = %v, want 405", rec.Code)
+	}
+}
@@ -5,49 +5,78 @@ import (
 	"errors"
 	"io"
 	"log/slog"
+	"math"
 	"os"
-	"sync"
+	"time"
 )
 
 type LoggerOptions struct {
-	AppName      string
-	Version      string
-	Level        string
-	Output       io.Writer
+	AppName string
+	Version string
+	Level   string
+	Output  io.Writer
+
+	// Output format: json (default), text or pretty, a colorized format for terminals
+	Format string
+
+	// Sinks with their own level and format, used instead of Output if set
+	Outputs []Output
+
+	// Minimum level per module, overriding Level, see Logger.Module
+	ModuleLevels map[string]string
+
 	DefaultAttrs map[string]any
 	Serializer   Serializer
+
+	// Location of the log timestamps, defaults to the local time
+	Location *time.Location
+
+	// Redaction of sensitive values, on with the default options if nil
+	Redact *RedactOptions
+
+	// Sampling of frequent records, off if nil
+	Sampling *SamplingOptions
+
+	// Collapsing of repeated errors, off if nil
+	Dedup *DedupOptions
+
+	// Called to exit after a fatal record, defaults to os.Exit
+	ExitFunc func(code int)
+
+	// Exit code after a fatal record, defaults to 1
+	FatalExitCode *int
+
+	// Max time for the shutdown hooks and output flushes before exiting on a fatal record
+	FatalTimeout *int
 }
 
+// Logger is immutable: With returns a new Logger, so it is safe to share between goroutines
 type Logger struct {
-	logger        *slog.Logger
-	contextLogger *slog.Logger
-	options       *LoggerOptions
-	ctxFence      sync.Mutex
+	logger   *slog.Logger
+	options  *LoggerOptions
+	levels   *Levels
+	shutdown *shutdown
 }
 
-const (
-	levelTrace    = slog.Level(-8)
-	levelFatal    = slog.Level(12)
-	levelCritical = slog.Level(16)
-)
-
-var levelNames = map[slog.Leveler]string{
-	levelTrace:    "TRACE",
-	levelFatal:    "FATAL",
-	levelCritical: "CRITICAL",
-}
+// Lowest slog level, the format handlers accept every record and levelHandler filters them
+const minSlogLevel = slog.Level(math.MinInt)
 
 func NewLogger(opts *LoggerOptions) (*Logger, error) {
 	var baseAttrs []any
-	var level slog.Level
+	var level Level
 	var handlerOpts *slog.HandlerOptions
 
-	level, err := getSlogLevel(opts.Level)
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	moduleLevels, err := parseModuleLevels(opts.ModuleLevels)
 	if err != nil {
 		return nil, err
 	}
 
-	if opts.Output == nil {
+	if opts.Output == nil && len(opts.Outputs) == 0 {
 		opts.Output = os.Stdout
 	}
 
@@ -61,20 +90,68 @@ func NewLogger(opts *LoggerOptions) (*Logger, error) {
 
 	baseAttrs = setupBaseAttrs(opts.AppName, opts.Version, opts.DefaultAttrs)
 
+	redactor, err := NewRedactor(opts.Redact)
+	if err != nil {
+		return nil, err
+	}
+
+	levels := NewLevels(level)
+	levels.SetModules(moduleLevels)
+
 	handlerOpts = &slog.HandlerOptions{
-		Level:       level,
-		ReplaceAttr: replaceCustomLevelNames,
+		Level: minSlogLevel,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			a = replaceCustomLevelNames(groups, a)
+			a = replaceTimeLocation(opts.Location, groups, a)
+			return redactor.ReplaceAttr(groups, a)
+		},
 	}
 
-	handler := slog.NewJSONHandler(opts.Output, handlerOpts)
+	outputHandler, err := newOutputHandler(opts, handlerOpts)
+	if err != nil {
+		return nil, err
+	}
+
+	var handler slog.Handler = NewContextHandler(outputHandler)
+
+	// the repeat counts are logged by the handler after the deduper, so they are not sampled
+	var recordDeduper *deduper
+	if opts.Dedup != nil {
+		recordDeduper, err = newDeduper(opts.Dedup)
+		if err != nil {
+			return nil, err
+		}
+
+		handler = &dedupHandler{next: handler, deduper: recordDeduper}
+	}
+
+	if opts.Sampling != nil {
+		recordSampler, err := newSampler(opts.Sampling)
+		if err != nil {
+			return nil, err
+		}
+
+		handler = &samplingHandler{next: handler, sampler: recordSampler}
+	}
+
+	handler = &levelHandler{next: handler, leveler: levels.Leveler("")}
 
 	logger := slog.New(handler)
 	logger = logger.With(baseAttrs...)
 
+	loggerShutdown := newShutdown(opts)
+	loggerShutdown.outputs = outputWriters(opts)
+
+	// logs the pending repeat counts, after the other hooks
+	if recordDeduper != nil {
+		loggerShutdown.hooks = append(loggerShutdown.hooks, recordDeduper.flush)
+	}
+
 	return &Logger{
-		logger:        logger,
-		contextLogger: logger,
-		options:       opts,
+		logger:   logger,
+		options:  opts,
+		levels:   levels,
+		shutdown: loggerShutdown,
 	}, nil
 }
 
@@ -102,40 +179,138 @@ func (l *Logger) Critical(msg string, attrs ...any) {
 	l.Log(context.TODO(), "critical", msg, attrs...)
 }
 
+// Fatal logs at the fatal level, then shuts the logger down and exits, see Log
 func (l *Logger) Fatal(msg string, attrs ...any) {
 	l.Log(context.TODO(), "fatal", msg, attrs...)
 }
 
+// Log logs msg at level. After a fatal record, the shutdown hooks are run, the outputs
+// flushed and the process exits with LoggerOptions.FatalExitCode.
 func (l *Logger) Log(ctx context.Context, level string, msg string, attrs ...any) error {
-	var slogLevel slog.Level
-	slogLevel, err := getSlogLevel(level)
+	var parsedLevel Level
+	parsedLevel, err := ParseLevel(level)
 	if err != nil {
 		return err
 	}
 
-	if len(attrs) == 1 {
-		if serializedAttr, ok := l.options.Serializer.Serialize(attrs[0]); ok {
-			attrs[0] = serializedAttr
-		}
+	if l.logger.Enabled(ctx, parsedLevel.Level()) {
+		l.logger.Log(ctx, parsedLevel.Level(), msg, l.serializeAttrs(attrs)...)
 	}
 
-	l.contextLogger.Log(ctx, slogLevel, msg, attrs...)
+	if parsedLevel >= LevelFatal {
+		l.exitAfterFatal()
+	}
 
 	return nil
 }
 
-func (l *Logger) AddLogContext(attrs ...any) {
-	l.ctxFence.Lock()
-	defer l.ctxFence.Unlock()
+// serializeAttrs runs every argument through the serializer. Arguments can be mixed freely:
+// key/value pairs (the key is kept), slog.Attrs and bare values of serializable types.
+func (l *Logger) serializeAttrs(attrs []any) []any {
+	serialized := make([]any, 0, len(attrs))
+
+	for i := 0; i < len(attrs); i++ {
+		switch a := attrs[i].(type) {
+		case string:
+			if i+1 == len(attrs) {
+				serialized = append(serialized, a)
+				continue
+			}
+
+			i++
+			if serializedAttr, ok := l.options.Serializer.Serialize(attrs[i]); ok {
+				serialized = append(serialized, slog.Attr{Key: a, Value: serializedAttr.Value})
+			} else {
+				serialized = append(serialized, a, attrs[i])
+			}
+
+		case slog.Attr:
+			if a.Value.Kind() != slog.KindAny {
+				serialized = append(serialized, a)
+				continue
+			}
+
+			if serializedAttr, ok := l.options.Serializer.Serialize(a.Value.Any()); ok {
+				serialized = append(serialized, slog.Attr{Key: a.Key, Value: serializedAttr.Value})
+			} else {
+				serialized = append(serialized, a)
+			}
+
+		default:
+			if serializedAttr, ok := l.options.Serializer.Serialize(a); ok {
+				serialized = append(serialized, serializedAttr)
+			} else {
+				serialized = append(serialized, a)
+			}
+		}
+	}
+
+	return serialized
+}
+
+// With returns a copy of the logger that adds attrs to every record
+func (l *Logger) With(attrs ...any) *Logger {
+	return &Logger{
+		logger:   l.logger.With(attrs...),
+		options:  l.options,
+		levels:   l.levels,
+		shutdown: l.shutdown,
+	}
+}
+
+// Module returns a copy of the logger for module, e.g. a package name, that adds it as the
+// module attr and logs from the module level, falling back to the base level
+func (l *Logger) Module(name string) *Logger {
+	handler := l.logger.Handler()
+	if moduleHandler, ok := handler.(*levelHandler); ok {
+		handler = moduleHandler.next
+	}
+
+	handler = &levelHandler{next: handler, leveler: l.levels.Leveler(name)}
+
+	return &Logger{
+		logger:   slog.New(handler).With("module", name),
+		options:  l.options,
+		levels:   l.levels,
+		shutdown: l.shutdown,
+	}
+}
+
+// SetLevel changes the minimum level logged, including by the base logger copies
+func (l *Logger) SetLevel(level string) error {
+	if l.levels == nil {
+		return errors.New("logger level is not adjustable")
+	}
+
+	parsedLevel, err := ParseLevel(level)
+	if err != nil {
+		return err
+	}
 
-	l.contextLogger = l.contextLogger.With(attrs...)
+	l.levels.Set("", parsedLevel)
+
+	return nil
 }
 
-func (l *Logger) ClearLogContext() {
-	l.ctxFence.Lock()
-	defer l.ctxFence.Unlock()
+// SetModuleLevels replaces the module levels set by LoggerOptions.ModuleLevels
+func (l *Logger) SetModuleLevels(moduleLevels map[string]string) error {
+	if l.levels == nil {
+		return errors.New("logger level is not adjustable")
+	}
+
+	parsedLevels, err := parseModuleLevels(moduleLevels)
+	if err != nil {
+		return err
+	}
+
+	l.levels.SetModules(parsedLevels)
 
-	l.contextLogger = l.logger
+	return nil
+}
+
+// Levels returns the levels of the logger and its modules, shared by all its copies
+func (l *Logger) Levels() *Levels {
+	return l.levels
 }
 
 func (l *Logger) GetBaseLogger() *slog.Logger {
@@ -158,6 +333,16 @@ func BuildAttrsFromMap(appAttrs map[string]any) []any {
 	return attrs
 }
 
+func BuildAttrsFromSlice(slogAttrs []slog.Attr) []any {
+	attrs := make([]any, 0, len(slogAttrs))
+
+	for _, a := range slogAttrs {
+		attrs = append(attrs, a)
+	}
+
+	return attrs
+}
+
 func setupBaseAttrs(appName string, version string, defaultAttrs map[string]any) []any {
 	var baseAttrs []any
 
@@ -170,35 +355,53 @@ func setupBaseAttrs(appName string, version string, defaultAttrs map[string]any)
 	return baseAttrs
 }
 
-func getSlogLevel(optLevel string) (slog.Level, error) {
-	switch optLevel {
-	case "trace":
-		return levelTrace, nil
-	case "debug":
-		return slog.LevelDebug, nil
-	case "info":
-		return slog.LevelInfo, nil
-	case "warn":
-		return slog.LevelWarn, nil
-	case "error":
-		return slog.LevelError, nil
-	case "critical":
-		return levelFatal, nil
-	case "fatal":
-		return levelCritical, nil
+func newOutputHandler(opts *LoggerOptions, handlerOpts *slog.HandlerOptions) (slog.Handler, error) {
+	if len(opts.Outputs) == 0 {
+		return newFormatHandler(opts.Format, opts.Output, handlerOpts)
+	}
+
+	return newMultiHandler(opts.Outputs, opts.Format, handlerOpts)
+}
+
+func outputWriters(opts *LoggerOptions) []io.Writer {
+	if len(opts.Outputs) == 0 {
+		return []io.Writer{opts.Output}
+	}
+
+	writers := make([]io.Writer, 0, len(opts.Outputs))
+	for _, output := range opts.Outputs {
+		writers = append(writers, output.Writer)
+	}
+
+	return writers
+}
+
+func newFormatHandler(format string, output io.Writer, handlerOpts *slog.HandlerOptions) (slog.Handler, error) {
+	switch format {
+	case "", "json":
+		return slog.NewJSONHandler(output, handlerOpts), nil
+	case "text":
+		return slog.NewTextHandler(output, handlerOpts), nil
+	case "pretty":
+		return NewPrettyHandler(output, handlerOpts), nil
 	default:
-		return -99, errors.New("unknown level")
+		return nil, errors.New("unknown format")
 	}
 }
 
+func replaceTimeLocation(location *time.Location, groups []string, a slog.Attr) slog.Attr {
+	if location != nil && len(groups) == 0 && a.Key == slog.TimeKey && a.Value.Kind() == slog.KindTime {
+		a.Value = slog.TimeValue(a.Value.Time().In(location))
+	}
+
+	return a
+}
+
 func replaceCustomLevelNames(groups []string, a slog.Attr) slog.Attr {
-	if a.Key == slog.LevelKey {
-		level := a.Value.Any().(slog.Level)
-		levelLabel, exists := levelNames[level]
-		if !exists {
-			levelLabel = level.String()
+	if a.Key == slog.LevelKey && len(groups) == 0 {
+		if level, ok := a.Value.Any().(slog.Level); ok {
+			a.Value = slog.StringValue(Level(level).String())
 		}
-		a.Value = slog.StringValue(levelLabel)
 	}
 
 	return a
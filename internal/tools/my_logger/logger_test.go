@@ -0,0 +1,69 @@
+package my_logger
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSerializeAttrs(t *testing.T) {
+	var output bytes.Buffer
+
+	logger, err := NewLogger(&LoggerOptions{Level: "info", Output: &output})
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/users", nil)
+
+	logger.Info("mixed",
+		"request", req,
+		errors.New("db down"),
+		slog.Any("response", &HttpResponseLogData{Time: time.Second, StatusCode: 503, Path: "/users"}),
+		slog.Int("attempt", 2),
+		"user", "bob",
+	)
+
+	records := loggedRecords(t, &output)
+	if len(records) != 1 {
+		t.Fatalf("logged %v records, want 1", len(records))
+	}
+	record := records[0]
+
+	tests := []struct {
+		name  string
+		group string
+		field string
+		want  any
+	}{
+		{"key/value pair keeps its key", "request", "path", "/users"},
+		{"bare value uses the serializer key", "err", "msg", "db down"},
+		{"slog.Attr keeps its key", "response", "status", float64(503)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group, ok := record[tt.group].(map[string]any)
+			if !ok {
+				t.Fatalf("record[%v] = %v, want a serialized group", tt.group, record[tt.group])
+			}
+
+			if group[tt.field] != tt.want {
+				t.Errorf("%v.%v = %v, want %v", tt.group, tt.field, group[tt.field], tt.want)
+			}
+		})
+	}
+
+	if record["attempt"] != float64(2) || record["user"] != "bob" {
+		t.Errorf("unserialized attrs = %v, %v, want them logged as they are", record["attempt"], record["user"])
+	}
+
+	for _, key := range []string{"req", "res", "!BADKEY"} {
+		if _, ok := record[key]; ok {
+			t.Errorf("record has %v, want every argument paired with its key: %v", key, record)
+		}
+	}
+}
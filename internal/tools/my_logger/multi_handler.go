@@ -0,0 +1,148 @@
+package my_logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync/atomic"
+)
+
+// Output is a sink of the logger, with its own level and format
+type Output struct {
+	// Name used in the write failure reports, defaults to the output index
+	Name string
+
+	Writer io.Writer
+
+	// Minimum level written, on top of the logger level. Every level if empty.
+	Level string
+
+	// Output format, defaults to LoggerOptions.Format
+	Format string
+}
+
+type sink struct {
+	name    string
+	handler slog.Handler
+	level   *Level
+
+	// whether the last write failed, so a broken sink is only reported once
+	failing *atomic.Bool
+}
+
+// multiHandler fans the records out to the sinks. A sink failing, even panicking, does
+// not keep the records from the other sinks.
+type multiHandler struct {
+	sinks []sink
+}
+
+func newMultiHandler(outputs []Output, defaultFormat string, handlerOpts *slog.HandlerOptions) (*multiHandler, error) {
+	sinks := make([]sink, 0, len(outputs))
+
+	for i, output := range outputs {
+		var level *Level
+
+		name := output.Name
+		if name == "" {
+			name = fmt.Sprint(i)
+		}
+
+		if output.Writer == nil {
+			return nil, fmt.Errorf("log output %v: missing writer", name)
+		}
+
+		format := output.Format
+		if format == "" {
+			format = defaultFormat
+		}
+
+		handler, err := newFormatHandler(format, output.Writer, handlerOpts)
+		if err != nil {
+			return nil, fmt.Errorf("log output %v: %w", name, err)
+		}
+
+		if output.Level != "" {
+			parsedLevel, err := ParseLevel(output.Level)
+			if err != nil {
+				return nil, fmt.Errorf("log output %v: %w", name, err)
+			}
+			level = &parsedLevel
+		}
+
+		sinks = append(sinks, sink{name: name, handler: handler, level: level, failing: new(atomic.Bool)})
+	}
+
+	return &multiHandler{sinks}, nil
+}
+
+func (m *multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, s := range m.sinks {
+		if s.accepts(level) && s.handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *multiHandler) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+
+	for _, s := range m.sinks {
+		if !s.accepts(r.Level) {
+			continue
+		}
+
+		err := s.handle(ctx, r.Clone())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("log output %v: %w", s.name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (m *multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	sinks := make([]sink, len(m.sinks))
+	for i, s := range m.sinks {
+		s.handler = s.handler.WithAttrs(attrs)
+		sinks[i] = s
+	}
+
+	return &multiHandler{sinks}
+}
+
+func (m *multiHandler) WithGroup(name string) slog.Handler {
+	sinks := make([]sink, len(m.sinks))
+	for i, s := range m.sinks {
+		s.handler = s.handler.WithGroup(name)
+		sinks[i] = s
+	}
+
+	return &multiHandler{sinks}
+}
+
+func (s sink) accepts(level slog.Level) bool {
+	return s.level == nil || level >= s.level.Level()
+}
+
+// handle writes r, reporting to stderr when the sink starts and stops failing
+func (s sink) handle(ctx context.Context, r slog.Record) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+
+		if err != nil && !s.failing.Swap(true) {
+			fmt.Fprintf(os.Stderr, "log output %v failed: %v\n", s.name, err)
+		}
+		if err == nil && s.failing.Swap(false) {
+			fmt.Fprintf(os.Stderr, "log output %v recovered\n", s.name)
+		}
+	}()
+
+	return s.handler.Handle(ctx, r)
+}
@@ -0,0 +1,91 @@
+package my_logger
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("sink down")
+}
+
+func TestMultiHandlerOutputs(t *testing.T) {
+	tests := []struct {
+		name       string
+		level      string
+		format     string
+		wantLines  int
+		wantPrefix string
+	}{
+		{"every level, default format", "", "", 4, `{"time"`},
+		{"warn and above", "warn", "", 3, `{"time"`},
+		{"critical only, text", "critical", "text", 1, "time="},
+		{"debug is below the logger level", "debug", "json", 4, `{"time"`},
+	}
+
+	var buffers []*bytes.Buffer
+	var outputs []Output
+	for _, tt := range tests {
+		buffer := &bytes.Buffer{}
+		buffers = append(buffers, buffer)
+		outputs = append(outputs, Output{Name: tt.name, Writer: buffer, Level: tt.level, Format: tt.format})
+	}
+
+	// a failing sink doesn't keep the records from the others
+	outputs = append(outputs, Output{Name: "failing", Writer: failingWriter{}})
+
+	logger, err := NewLogger(&LoggerOptions{Level: "info", Format: "json", Outputs: outputs})
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+
+	logger.Debug("debug record")
+	logger.Info("info record")
+	logger.Warn("warn record")
+	logger.Error("error record")
+	logger.Critical("critical record")
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := strings.Split(strings.TrimSpace(buffers[i].String()), "\n")
+			if buffers[i].Len() == 0 {
+				lines = nil
+			}
+
+			if len(lines) != tt.wantLines {
+				t.Fatalf("wrote %v records, want %v: %q", len(lines), tt.wantLines, lines)
+			}
+
+			for _, line := range lines {
+				if !strings.HasPrefix(line, tt.wantPrefix) {
+					t.Errorf("record %q not in the output format", line)
+				}
+			}
+		})
+	}
+}
+
+func TestMultiHandlerInvalidOutputs(t *testing.T) {
+	tests := []struct {
+		name   string
+		output Output
+	}{
+		{"missing writer", Output{Name: "nowhere"}},
+		{"unknown level", Output{Writer: &bytes.Buffer{}, Level: "loud"}},
+		{"unknown format", Output{Writer: &bytes.Buffer{}, Format: "xml"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewLogger(&LoggerOptions{Level: "info", Outputs: []Output{tt.output}})
+			if err == nil {
+				t.Errorf("NewLogger() accepted output %+v", tt.output)
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package my_logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ANSI escape codes used by the pretty format
+const (
+	ansiReset   = "\033[0m"
+	ansiBold    = "\033[1m"
+	ansiDim     = "\033[2m"
+	ansiRed     = "\033[31m"
+	ansiGreen   = "\033[32m"
+	ansiYellow  = "\033[33m"
+	ansiBlue    = "\033[34m"
+	ansiMagenta = "\033[35m"
+	ansiCyan    = "\033[36m"
+)
+
+// Width the level labels are padded to, so messages line up
+const pretty_level_width = 8
+
+type prettyAttr struct {
+	groups []string
+	attr   slog.Attr
+}
+
+// PrettyHandler writes human-readable, colorized records for local development: one line
+// with the time, aligned level, message and plain attrs, followed by one indented line per
+// group (e.g. req and res) and the multi-line values, such as error stacks. Colors are
+// disabled when NO_COLOR is set.
+type PrettyHandler struct {
+	out    io.Writer
+	opts   slog.HandlerOptions
+	attrs  []prettyAttr
+	groups []string
+	color  bool
+	mu     *sync.Mutex
+}
+
+func NewPrettyHandler(out io.Writer, opts *slog.HandlerOptions) *PrettyHandler {
+	if opts == nil {
+		opts = &slog.HandlerOptions{}
+	}
+
+	return &PrettyHandler{
+		out:   out,
+		opts:  *opts,
+		color: os.Getenv("NO_COLOR") == "",
+		mu:    &sync.Mutex{},
+	}
+}
+
+func (p *PrettyHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if p.opts.Level != nil {
+		minLevel = p.opts.Level.Level()
+	}
+
+	return level >= minLevel
+}
+
+func (p *PrettyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handler := *p
+	handler.attrs = slices.Clone(p.attrs)
+
+	for _, attr := range attrs {
+		handler.attrs = append(handler.attrs, prettyAttr{p.groups, attr})
+	}
+
+	return &handler
+}
+
+func (p *PrettyHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return p
+	}
+
+	handler := *p
+	handler.groups = append(slices.Clone(p.groups), name)
+
+	return &handler
+}
+
+func (p *PrettyHandler) Handle(_ context.Context, r slog.Record) error {
+	var inline []string
+	var blocks []string
+
+	buf := &bytes.Buffer{}
+
+	timeAttr := p.replace(nil, slog.Time(slog.TimeKey, r.Time))
+	if !timeAttr.Equal(slog.Attr{}) {
+		timeLabel := timeAttr.Value.String()
+		if timeAttr.Value.Kind() == slog.KindTime {
+			timeLabel = timeAttr.Value.Time().Format(time.TimeOnly + ".000")
+		}
+		buf.WriteString(p.paint(ansiDim, timeLabel) + " ")
+	}
+
+	levelLabel := p.replace(nil, slog.Any(slog.LevelKey, r.Level)).Value.String()
+	padding := strings.Repeat(" ", max(pretty_level_width-len(levelLabel), 0))
+	buf.WriteString(p.paint(levelColor(r.Level), levelLabel) + padding)
+	buf.WriteString(p.paint(ansiBold, r.Message))
+
+	for _, a := range p.attrs {
+		p.appendAttr(&inline, &blocks, a.groups, a.attr)
+	}
+
+	r.Attrs(func(attr slog.Attr) bool {
+		p.appendAttr(&inline, &blocks, p.groups, attr)
+		return true
+	})
+
+	if len(inline) > 0 {
+		buf.WriteString(" " + strings.Join(inline, " "))
+	}
+	buf.WriteString("\n")
+
+	for _, block := range blocks {
+		buf.WriteString(block + "\n")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	_, err := p.out.Write(buf.Bytes())
+
+	return err
+}
+
+// appendAttr formats attr: top level groups and multi-line values go to their own block,
+// everything else inline as key=value
+func (p *PrettyHandler) appendAttr(inline *[]string, blocks *[]string, groups []string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+
+	if attr.Value.Kind() != slog.KindGroup {
+		attr = p.replace(groups, attr)
+		if attr.Equal(slog.Attr{}) {
+			return
+		}
+	}
+
+	key := strings.Join(append(slices.Clone(groups), attr.Key), ".")
+
+	switch {
+	case attr.Value.Kind() == slog.KindGroup:
+		groupAttrs := attr.Value.Group()
+		if len(groupAttrs) == 0 {
+			return
+		}
+
+		// inline groups (empty key) are merged into the parent
+		if attr.Key == "" {
+			for _, groupAttr := range groupAttrs {
+				p.appendAttr(inline, blocks, groups, groupAttr)
+			}
+			return
+		}
+
+		var groupInline []string
+		var groupBlocks []string
+		for _, groupAttr := range groupAttrs {
+			p.appendAttr(&groupInline, &groupBlocks, append(slices.Clone(groups), attr.Key), groupAttr)
+		}
+
+		if len(groupInline) > 0 {
+			*blocks = append(*blocks, "    "+p.paint(ansiCyan, key+":")+" "+strings.Join(groupInline, " "))
+		}
+		*blocks = append(*blocks, groupBlocks...)
+
+	case attr.Value.Kind() == slog.KindString && strings.Contains(attr.Value.String(), "\n"):
+		*blocks = append(*blocks, "    "+p.paint(ansiCyan, key+":"))
+		for _, line := range strings.Split(strings.TrimRight(attr.Value.String(), "\n"), "\n") {
+			*blocks = append(*blocks, "      "+p.paint(ansiDim, line))
+		}
+
+	default:
+		*inline = append(*inline, p.paint(ansiDim, attr.Key+"=")+formatPrettyValue(attr.Value))
+	}
+}
+
+func (p *PrettyHandler) replace(groups []string, attr slog.Attr) slog.Attr {
+	if p.opts.ReplaceAttr == nil {
+		return attr
+	}
+
+	return p.opts.ReplaceAttr(groups, attr)
+}
+
+func (p *PrettyHandler) paint(color string, s string) string {
+	if !p.color {
+		return s
+	}
+
+	return color + s + ansiReset
+}
+
+func formatPrettyValue(v slog.Value) string {
+	var formatted string
+
+	switch v.Kind() {
+	case slog.KindTime:
+		formatted = v.Time().Format(time.RFC3339)
+	default:
+		formatted = fmt.Sprint(v.Any())
+	}
+
+	if strings.ContainsAny(formatted, " \t\"=") || formatted == "" {
+		return fmt.Sprintf("%q", formatted)
+	}
+
+	return formatted
+}
+
+func levelColor(level slog.Level) string {
+	switch {
+	case level >= LevelCritical.Level():
+		return ansiMagenta
+	case level >= slog.LevelError:
+		return ansiRed
+	case level >= slog.LevelWarn:
+		return ansiYellow
+	case level >= slog.LevelInfo:
+		return ansiGreen
+	default:
+		return ansiBlue
+	}
+}
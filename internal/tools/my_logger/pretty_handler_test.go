@@ -0,0 +1,100 @@
+package my_logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// dropTime removes the record time, so the output is deterministic
+func dropTime(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) == 0 && a.Key == slog.TimeKey {
+		return slog.Attr{}
+	}
+
+	return a
+}
+
+func TestPrettyHandler(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	req := httptest.NewRequest("GET", "/users", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("User-Agent", "curl/8")
+
+	tests := []struct {
+		name  string
+		level slog.Level
+		msg   string
+		attrs []any
+		want  []string
+	}{
+		{
+			name:  "inline attrs",
+			level: slog.LevelInfo,
+			msg:   "started",
+			attrs: []any{"port", 8080, "env", "local dev"},
+			want:  []string{`INFO    started port=8080 env="local dev"`},
+		},
+		{
+			name:  "req and res groups",
+			level: slog.LevelInfo,
+			msg:   "request",
+			attrs: []any{
+				serializeHttpRequest(req),
+				serializeHttpResponse(&HttpResponseLogData{Time: 15 * time.Millisecond, StatusCode: 200, Path: "/users"}),
+				"attempt", 2,
+			},
+			want: []string{
+				"INFO    request attempt=2",
+				"    req: method=GET path=/users ip=10.0.0.1:1234 user-agent=curl/8",
+				"    res: status=200 path=/users time=15ms",
+			},
+		},
+		{
+			name:  "multi-line stack",
+			level: slog.LevelError,
+			msg:   "failed",
+			attrs: []any{slog.Group("err", slog.String("msg", "boom"), slog.String("stack", "goroutine 1:\nmain.main()\n\tmain.go:10\n"))},
+			want: []string{
+				"ERROR   failed",
+				"    err: msg=boom",
+				"    err.stack:",
+				"      goroutine 1:",
+				"      main.main()",
+				"      \tmain.go:10",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+
+			logger := slog.New(NewPrettyHandler(&output, &slog.HandlerOptions{ReplaceAttr: dropTime}))
+			logger.Log(context.Background(), tt.level, tt.msg, tt.attrs...)
+
+			got := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("output:\n%v\nwant:\n%v", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+func TestPrettyHandlerColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+
+	var output bytes.Buffer
+
+	logger := slog.New(NewPrettyHandler(&output, &slog.HandlerOptions{ReplaceAttr: dropTime}))
+	logger.Warn("slow")
+
+	if want := ansiYellow + "WARN" + ansiReset; !strings.Contains(output.String(), want) {
+		t.Errorf("output = %q, want the level colored %q", output.String(), want)
+	}
+}
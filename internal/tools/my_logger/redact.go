@@ -0,0 +1,271 @@
+package my_logger
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Max depth of the values walked looking for fields to redact
+const max_redact_depth = 8
+
+// Attr keys redacted by default, matched case-insensitively anywhere in the key
+var default_redact_keys = []string{"password", "passwd", "secret", "token", "authorization", "cookie", "api[-_]?key"}
+
+// MaskStrategy defines how a redacted value is masked
+type MaskStrategy string
+
+const (
+	// Replaces the whole value
+	MaskFull MaskStrategy = "full"
+
+	// Keeps the first and last 2 characters of values with 8 or more characters
+	MaskPartial MaskStrategy = "partial"
+
+	// Replaces the value with a short HMAC-SHA256, so equal values can still be correlated
+	MaskHash MaskStrategy = "hash"
+)
+
+const masked_value = "[REDACTED]"
+
+type RedactOptions struct {
+	// Patterns of the attr and field keys to redact, matched case-insensitively. Defaults to
+	// common credential names such as password, token and authorization.
+	Keys []string
+
+	// Masking strategy, defaults to MaskFull. Struct fields can override it with
+	// `log:"redact,partial"` or `log:"redact,hash"`.
+	Strategy MaskStrategy
+
+	// Key of the MaskHash HMAC. Defaults to a random key, so the hashes only correlate
+	// within a process.
+	HashKey []byte
+
+	// Turns redaction off
+	Disabled bool
+}
+
+// Redactor masks sensitive values in log records: attrs whose keys match the configured
+// patterns, and struct fields tagged `log:"redact"`. Fields tagged `log:"-"` are omitted.
+// It runs as part of the handler ReplaceAttr, so the output of every serializer goes
+// through it too.
+type Redactor struct {
+	keyPatterns []*regexp.Regexp
+	strategy    MaskStrategy
+	hashKey     []byte
+	disabled    bool
+}
+
+func NewRedactor(opts *RedactOptions) (*Redactor, error) {
+	if opts == nil {
+		opts = &RedactOptions{}
+	}
+
+	keys := opts.Keys
+	if keys == nil {
+		keys = default_redact_keys
+	}
+
+	strategy := opts.Strategy
+	if strategy == "" {
+		strategy = MaskFull
+	}
+
+	if strategy != MaskFull && strategy != MaskPartial && strategy != MaskHash {
+		return nil, fmt.Errorf("unknown mask strategy %q", strategy)
+	}
+
+	keyPatterns := make([]*regexp.Regexp, 0, len(keys))
+	for _, key := range keys {
+		pattern, err := regexp.Compile("(?i)" + key)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redact key pattern %q: %w", key, err)
+		}
+
+		keyPatterns = append(keyPatterns, pattern)
+	}
+
+	hashKey := opts.HashKey
+	if len(hashKey) == 0 {
+		hashKey = make([]byte, sha256.Size)
+
+		_, err := rand.Read(hashKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Redactor{keyPatterns, strategy, hashKey, opts.Disabled}, nil
+}
+
+// ReplaceAttr redacts a, to be used as (or in) slog.HandlerOptions.ReplaceAttr
+func (r *Redactor) ReplaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if r.disabled {
+		return a
+	}
+
+	// built-in keys are never redacted
+	if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == slog.LevelKey || a.Key == slog.MessageKey || a.Key == slog.SourceKey) {
+		return a
+	}
+
+	if r.matchesKey(a.Key) {
+		return slog.String(a.Key, r.Mask(a.Value.String(), r.strategy))
+	}
+
+	if a.Value.Kind() == slog.KindAny {
+		a.Value = slog.AnyValue(r.redactValue(reflect.ValueOf(a.Value.Any()), 0))
+	}
+
+	return a
+}
+
+// Mask masks value with strategy
+func (r *Redactor) Mask(value string, strategy MaskStrategy) string {
+	switch strategy {
+	case MaskPartial:
+		runes := []rune(value)
+		if len(runes) < 8 {
+			return masked_value
+		}
+		return string(runes[:2]) + strings.Repeat("*", len(runes)-4) + string(runes[len(runes)-2:])
+
+	case MaskHash:
+		mac := hmac.New(sha256.New, r.hashKey)
+		mac.Write([]byte(value))
+		return "hmac:" + hex.EncodeToString(mac.Sum(nil))[:16]
+
+	default:
+		return masked_value
+	}
+}
+
+func (r *Redactor) matchesKey(key string) bool {
+	for _, pattern := range r.keyPatterns {
+		if pattern.MatchString(key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redactValue returns value with its sensitive fields masked. Structs are converted to maps,
+// other values are returned as is.
+func (r *Redactor) redactValue(value reflect.Value, depth int) any {
+	if !value.IsValid() {
+		return nil
+	}
+
+	if depth > max_redact_depth {
+		return value.Interface()
+	}
+
+	switch value.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() || value.Elem().Kind() != reflect.Struct {
+			return value.Interface()
+		}
+		if hasOwnFormat(value.Interface()) {
+			return value.Interface()
+		}
+		return r.redactValue(value.Elem(), depth+1)
+
+	case reflect.Struct:
+		if hasOwnFormat(value.Interface()) {
+			return value.Interface()
+		}
+
+		valueType := value.Type()
+		redacted := make(map[string]any, valueType.NumField())
+
+		for i := range valueType.NumField() {
+			field := valueType.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			tag, options, _ := strings.Cut(field.Tag.Get("log"), ",")
+			if tag == "-" {
+				continue
+			}
+
+			name := redactFieldName(field)
+			fieldValue := value.Field(i)
+
+			if tag == "redact" || r.matchesKey(name) {
+				strategy := r.strategy
+				if options != "" {
+					strategy = MaskStrategy(options)
+				}
+
+				redacted[name] = r.Mask(fmt.Sprint(fieldValue.Interface()), strategy)
+				continue
+			}
+
+			redacted[name] = r.redactValue(fieldValue, depth+1)
+		}
+
+		return redacted
+
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return value.Interface()
+		}
+
+		redacted := make(map[string]any, value.Len())
+		for _, key := range value.MapKeys() {
+			if r.matchesKey(key.String()) {
+				redacted[key.String()] = r.Mask(fmt.Sprint(value.MapIndex(key).Interface()), r.strategy)
+				continue
+			}
+
+			redacted[key.String()] = r.redactValue(value.MapIndex(key), depth+1)
+		}
+
+		return redacted
+
+	case reflect.Slice, reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			return value.Interface()
+		}
+
+		redacted := make([]any, 0, value.Len())
+		for i := range value.Len() {
+			redacted = append(redacted, r.redactValue(value.Index(i), depth+1))
+		}
+
+		return redacted
+
+	default:
+		return value.Interface()
+	}
+}
+
+// hasOwnFormat reports whether v controls how it is logged, e.g. errors and time.Time, in
+// which case it is logged as it is
+func hasOwnFormat(v any) bool {
+	switch v.(type) {
+	case error, fmt.Stringer, json.Marshaler, encoding.TextMarshaler:
+		return true
+	default:
+		return false
+	}
+}
+
+func redactFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+
+	return name
+}
@@ -0,0 +1,156 @@
+package my_logger
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/bermr/api-golang-base/internal/config"
+)
+
+type redactTagged struct {
+	Name    string `json:"name"`
+	Secret  string `json:"secret_value" log:"redact"`
+	Card    string `json:"card" log:"redact,partial"`
+	Ignored string `json:"ignored" log:"-"`
+}
+
+func TestRedactKeys(t *testing.T) {
+	var output bytes.Buffer
+
+	logger, err := NewLogger(&LoggerOptions{Level: "info", Output: &output})
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+
+	logger.With("api_key", "with-secret").Info("request",
+		"user", "bob",
+		slog.Group("req", slog.String("Authorization", "Bearer group-secret"), slog.String("path", "/users")),
+		"headers", map[string]any{"Cookie": "map-secret", "accept": "*/*"},
+	)
+
+	records := loggedRecords(t, &output)
+	if len(records) != 1 {
+		t.Fatalf("logged %v records, want 1", len(records))
+	}
+	record := records[0]
+
+	if strings.Contains(output.String(), "secret") {
+		t.Errorf("output has an unredacted value: %v", output.String())
+	}
+
+	if record["api_key"] != masked_value {
+		t.Errorf("With attr api_key = %v, want %v", record["api_key"], masked_value)
+	}
+
+	req := record["req"].(map[string]any)
+	if req["Authorization"] != masked_value || req["path"] != "/users" {
+		t.Errorf("group req = %v, want only Authorization redacted", req)
+	}
+
+	headers := record["headers"].(map[string]any)
+	if headers["Cookie"] != masked_value || headers["accept"] != "*/*" {
+		t.Errorf("headers = %v, want only Cookie redacted", headers)
+	}
+
+	if record["user"] != "bob" {
+		t.Errorf("user = %v, want bob", record["user"])
+	}
+}
+
+func TestRedactTags(t *testing.T) {
+	var output bytes.Buffer
+
+	logger, err := NewLogger(&LoggerOptions{Level: "info", Output: &output})
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+
+	logger.Info("tagged", "value", redactTagged{Name: "bob", Secret: "s3cr3t", Card: "4111111111111111", Ignored: "dropped"})
+
+	value := loggedRecords(t, &output)[0]["value"].(map[string]any)
+
+	want := map[string]any{"name": "bob", "secret_value": masked_value, "card": "41************11"}
+	for key, wantValue := range want {
+		if value[key] != wantValue {
+			t.Errorf("value.%v = %v, want %v", key, value[key], wantValue)
+		}
+	}
+
+	if _, ok := value["ignored"]; ok {
+		t.Errorf("field tagged log:\"-\" logged: %v", value)
+	}
+}
+
+func TestRedactDbConnConfig(t *testing.T) {
+	var db config.DbConnConfig
+	db.Database = "app"
+	db.Host.Write = "db.internal"
+	db.Port = "3306"
+	db.Username = "app_user"
+	db.Password = "hunter2hunter2"
+
+	for _, format := range []string{"json", "text", "pretty"} {
+		t.Run(format, func(t *testing.T) {
+			var output bytes.Buffer
+
+			logger, err := NewLogger(&LoggerOptions{Level: "info", Format: format, Output: &output})
+			if err != nil {
+				t.Fatalf("NewLogger() error = %v", err)
+			}
+
+			logger.Info("connecting", "db", db)
+
+			if strings.Contains(output.String(), db.Password) {
+				t.Errorf("password logged: %v", output.String())
+			}
+			if !strings.Contains(output.String(), masked_value) || !strings.Contains(output.String(), "db.internal") {
+				t.Errorf("want the config logged with only the password redacted, got %v", output.String())
+			}
+		})
+	}
+}
+
+func TestRedactorMask(t *testing.T) {
+	redactor, err := NewRedactor(&RedactOptions{HashKey: []byte("key")})
+	if err != nil {
+		t.Fatalf("NewRedactor() error = %v", err)
+	}
+
+	otherKeyRedactor, err := NewRedactor(&RedactOptions{HashKey: []byte("other key")})
+	if err != nil {
+		t.Fatalf("NewRedactor() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		value    string
+		strategy MaskStrategy
+		want     string
+	}{
+		{"full", "password", MaskFull, masked_value},
+		{"partial", "abcdefghij", MaskPartial, "ab******ij"},
+		{"partial keeps whole runes", "ñandúçãoé", MaskPartial, "ña*****oé"},
+		{"partial short value", "abc", MaskPartial, masked_value},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := redactor.Mask(tt.value, tt.strategy); got != tt.want {
+				t.Errorf("Mask(%q, %v) = %q, want %q", tt.value, tt.strategy, got, tt.want)
+			}
+		})
+	}
+
+	hash := redactor.Mask("password", MaskHash)
+	if !strings.HasPrefix(hash, "hmac:") || strings.Contains(hash, "password") {
+		t.Errorf("Mask(hash) = %q, want an hmac", hash)
+	}
+	if again := redactor.Mask("password", MaskHash); again != hash {
+		t.Errorf("Mask(hash) = %q then %q, want equal values to correlate", hash, again)
+	}
+	if other := otherKeyRedactor.Mask("password", MaskHash); other == hash {
+		t.Errorf("Mask(hash) = %q with both keys, want it to depend on the key", hash)
+	}
+}
@@ -0,0 +1,431 @@
+package my_logger
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Default rotation params
+const (
+	default_rotating_max_bytes   = 100 * 1024 * 1024 // 100 MB
+	default_rotating_max_backups = 7
+
+	rotated_file_time_layout = "20060102T150405.000"
+)
+
+type RotatingFileOptions struct {
+	// Path of the current log file. Rotated files are renamed with the rotation time,
+	// e.g. app.log to app-20060102T150405.000.log, and a sequence number if that name is
+	// taken, e.g. app-20060102T150405.000-1.log
+	Path string
+
+	// File size that triggers a rotation, in bytes
+	MaxBytes *int
+
+	// Time between rotations, in ms. No time based rotation if nil.
+	RotateEvery *int
+
+	// Rotated files kept, the oldest are removed. 0 keeps every file.
+	MaxBackups *int
+
+	// Gzip the rotated files
+	Compress bool
+
+	// Reopen the file on SIGHUP, e.g. after an external logrotate moved it
+	ReopenOnHangup bool
+}
+
+// RotatingFile is an io.Writer appending to a file, rotated by size and time
+type RotatingFile struct {
+	options     RotatingFileOptions
+	maxBytes    int64
+	rotateEvery time.Duration
+	maxBackups  int
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+
+	// compression and removal of the rotated files, in the background
+	cleanupMu sync.Mutex
+	cleanupWg sync.WaitGroup
+
+	// reopening on SIGHUP, stopped by Close
+	hangups   chan os.Signal
+	done      chan struct{}
+	hangupsWg sync.WaitGroup
+}
+
+// rotatedFile is a backup of the file, ordered by rotation time then sequence
+type rotatedFile struct {
+	path      string
+	rotatedAt time.Time
+	sequence  int
+}
+
+func NewRotatingFile(opts RotatingFileOptions) (*RotatingFile, error) {
+	if opts.Path == "" {
+		return nil, errors.New("rotating file path is required")
+	}
+
+	rotatingFile := &RotatingFile{
+		options:    opts,
+		maxBytes:   default_rotating_max_bytes,
+		maxBackups: default_rotating_max_backups,
+	}
+
+	if opts.MaxBytes != nil {
+		if *opts.MaxBytes <= 0 {
+			return nil, errors.New("rotating file max bytes must be positive")
+		}
+		rotatingFile.maxBytes = int64(*opts.MaxBytes)
+	}
+
+	if opts.RotateEvery != nil {
+		if *opts.RotateEvery <= 0 {
+			return nil, errors.New("rotating file interval must be positive")
+		}
+		rotatingFile.rotateEvery = time.Millisecond * time.Duration(*opts.RotateEvery)
+	}
+
+	if opts.MaxBackups != nil {
+		rotatingFile.maxBackups = *opts.MaxBackups
+	}
+
+	err := os.MkdirAll(filepath.Dir(opts.Path), 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	err = rotatingFile.open()
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.ReopenOnHangup {
+		rotatingFile.hangups = make(chan os.Signal, 1)
+		rotatingFile.done = make(chan struct{})
+		signal.Notify(rotatingFile.hangups, syscall.SIGHUP)
+
+		rotatingFile.hangupsWg.Add(1)
+		go func() {
+			defer rotatingFile.hangupsWg.Done()
+
+			for {
+				select {
+				case <-rotatingFile.done:
+					return
+				case <-rotatingFile.hangups:
+					err := rotatingFile.Reopen()
+					if err != nil && !errors.Is(err, os.ErrClosed) {
+						fmt.Fprintf(os.Stderr, "log file reopen error: %v\n", err)
+					}
+				}
+			}
+		}()
+	}
+
+	return rotatingFile, nil
+}
+
+// Write appends p to the file, rotating it first if p would exceed the max size or the
+// rotation interval is over. A single write bigger than the max size is not split.
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return 0, os.ErrClosed
+	}
+
+	sizeExceeded := r.size > 0 && r.size+int64(len(p)) > r.maxBytes
+	intervalOver := r.rotateEvery > 0 && time.Since(r.openedAt) >= r.rotateEvery
+
+	if sizeExceeded || intervalOver {
+		err := r.rotate()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+
+	return n, err
+}
+
+// Rotate renames the current file with the rotation time and starts a new one
+func (r *RotatingFile) Rotate() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return os.ErrClosed
+	}
+
+	return r.rotate()
+}
+
+// Reopen closes and reopens the file at the same path, without rotating it
+func (r *RotatingFile) Reopen() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return os.ErrClosed
+	}
+
+	err := r.file.Close()
+	if err != nil {
+		return err
+	}
+
+	return r.open()
+}
+
+func (r *RotatingFile) Sync() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return os.ErrClosed
+	}
+
+	return r.file.Sync()
+}
+
+// Close closes the file, stops the reopening on SIGHUP and waits for the rotated files to
+// be compressed and removed. It can be called more than once.
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+
+	if r.file == nil {
+		r.mu.Unlock()
+		return nil
+	}
+
+	if r.done != nil {
+		signal.Stop(r.hangups)
+		close(r.done)
+	}
+
+	err := r.file.Close()
+	r.file = nil
+
+	r.mu.Unlock()
+
+	// outside the lock, an ongoing reopen waits for it
+	r.hangupsWg.Wait()
+	r.cleanupWg.Wait()
+
+	return err
+}
+
+// open opens the file for appending. Must hold the lock.
+func (r *RotatingFile) open() error {
+	file, err := os.OpenFile(r.options.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	r.file = file
+	r.size = info.Size()
+	r.openedAt = time.Now()
+
+	return nil
+}
+
+// rotate renames the file and opens a new one. Must hold the lock.
+func (r *RotatingFile) rotate() error {
+	err := r.file.Close()
+	if err != nil {
+		return err
+	}
+
+	rotatedPath := r.rotatedPath(time.Now())
+
+	err = os.Rename(r.options.Path, rotatedPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	err = r.open()
+	if err != nil {
+		return err
+	}
+
+	r.cleanupWg.Add(1)
+	go func() {
+		defer r.cleanupWg.Done()
+
+		err := r.cleanup(rotatedPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "log file cleanup error: %v\n", err)
+		}
+	}()
+
+	return nil
+}
+
+// rotatedPath returns a free path for the file rotated at rotatedAt, adding a sequence
+// number when rotated more than once in the same ms
+func (r *RotatingFile) rotatedPath(rotatedAt time.Time) string {
+	ext := filepath.Ext(r.options.Path)
+	base := fmt.Sprintf("%v-%v", strings.TrimSuffix(r.options.Path, ext), rotatedAt.Format(rotated_file_time_layout))
+
+	rotatedPath := base + ext
+	for sequence := 1; fileExists(rotatedPath) || fileExists(rotatedPath+".gz"); sequence++ {
+		rotatedPath = fmt.Sprintf("%v-%v%v", base, sequence, ext)
+	}
+
+	return rotatedPath
+}
+
+// cleanup compresses the rotated file, if enabled, and removes the oldest backups
+func (r *RotatingFile) cleanup(rotatedPath string) error {
+	r.cleanupMu.Lock()
+	defer r.cleanupMu.Unlock()
+
+	if r.options.Compress {
+		err := compressFile(rotatedPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	if r.maxBackups <= 0 {
+		return nil
+	}
+
+	backups, err := r.backups()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for len(backups) > r.maxBackups {
+		err := os.Remove(backups[0].path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+		backups = backups[1:]
+	}
+
+	return errors.Join(errs...)
+}
+
+// backups lists the rotated files, compressed or not, oldest first
+func (r *RotatingFile) backups() ([]rotatedFile, error) {
+	ext := filepath.Ext(r.options.Path)
+	prefix := filepath.Base(strings.TrimSuffix(r.options.Path, ext)) + "-"
+	dir := filepath.Dir(r.options.Path)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var backups []rotatedFile
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		suffix := strings.TrimSuffix(strings.TrimSuffix(name, ".gz"), ext)
+		suffix = strings.TrimPrefix(suffix, prefix)
+
+		backup, ok := parseRotatedFile(suffix)
+		if ok {
+			backup.path = filepath.Join(dir, name)
+			backups = append(backups, backup)
+		}
+	}
+
+	slices.SortFunc(backups, func(a, b rotatedFile) int {
+		if c := a.rotatedAt.Compare(b.rotatedAt); c != 0 {
+			return c
+		}
+		return a.sequence - b.sequence
+	})
+
+	return backups, nil
+}
+
+// parseRotatedFile parses the rotation time and sequence suffix of a rotated file name,
+// e.g. 20060102T150405.000-1
+func parseRotatedFile(suffix string) (rotatedFile, bool) {
+	var backup rotatedFile
+	var err error
+
+	rotatedAt, sequence, hasSequence := strings.Cut(suffix, "-")
+
+	backup.rotatedAt, err = time.Parse(rotated_file_time_layout, rotatedAt)
+	if err != nil {
+		return backup, false
+	}
+
+	if hasSequence {
+		backup.sequence, err = strconv.Atoi(sequence)
+		if err != nil || backup.sequence < 1 {
+			return backup, false
+		}
+	}
+
+	return backup, true
+}
+
+func fileExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
+
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+
+	gzipWriter := gzip.NewWriter(dst)
+
+	_, err = io.Copy(gzipWriter, src)
+	if err == nil {
+		err = gzipWriter.Close()
+	}
+	if err == nil {
+		err = dst.Close()
+	} else {
+		dst.Close()
+	}
+
+	if err != nil {
+		os.Remove(path + ".gz")
+		return err
+	}
+
+	return os.Remove(path)
+}
@@ -0,0 +1,234 @@
+package my_logger
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func newTestRotatingFile(t *testing.T, opts RotatingFileOptions) *RotatingFile {
+	t.Helper()
+
+	rotatingFile, err := NewRotatingFile(opts)
+	if err != nil {
+		t.Fatalf("NewRotatingFile() error = %v", err)
+	}
+	t.Cleanup(func() { rotatingFile.Close() })
+
+	return rotatingFile
+}
+
+// rotatedFiles lists the names of the rotated files, oldest first
+func rotatedFiles(t *testing.T, rotatingFile *RotatingFile) []string {
+	t.Helper()
+
+	backups, err := rotatingFile.backups()
+	if err != nil {
+		t.Fatalf("listing the rotated files: %v", err)
+	}
+
+	names := make([]string, 0, len(backups))
+	for _, backup := range backups {
+		names = append(names, filepath.Base(backup.path))
+	}
+
+	return names
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("reading %v: %v", path, err)
+	}
+
+	return string(content)
+}
+
+func TestRotatingFileSizeRotation(t *testing.T) {
+	tests := []struct {
+		name        string
+		writes      []string
+		wantBackups int
+		wantCurrent string
+	}{
+		{"below the max size", []string{"12345", "12345"}, 0, "1234512345"},
+		{"rotated before exceeding the max size", []string{"12345", "123456"}, 1, "123456"},
+		{"bigger write than the max size is not split", []string{"123456789012"}, 0, "123456789012"},
+		{"rotated on every write", []string{"1234567890", "abcdefghij", "klmnopqrst"}, 2, "klmnopqrst"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "app.log")
+			maxBytes, maxBackups := 10, 0
+
+			rotatingFile := newTestRotatingFile(t, RotatingFileOptions{Path: path, MaxBytes: &maxBytes, MaxBackups: &maxBackups})
+
+			for _, write := range tt.writes {
+				_, err := rotatingFile.Write([]byte(write))
+				if err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+			}
+
+			if got := rotatedFiles(t, rotatingFile); len(got) != tt.wantBackups {
+				t.Errorf("rotated files = %v, want %v", got, tt.wantBackups)
+			}
+			if got := readFile(t, path); got != tt.wantCurrent {
+				t.Errorf("current file = %q, want %q", got, tt.wantCurrent)
+			}
+		})
+	}
+}
+
+func TestRotatingFileRetention(t *testing.T) {
+	tests := []struct {
+		name        string
+		maxBackups  int
+		compress    bool
+		wantBackups []string
+	}{
+		{"keeps the newest", 2, false, []string{"3", "4"}},
+		{"keeps every file", 0, false, []string{"0", "1", "2", "3", "4"}},
+		{"compressed", 2, true, []string{"3", "4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "app.log")
+
+			rotatingFile, err := NewRotatingFile(RotatingFileOptions{Path: path, MaxBackups: &tt.maxBackups, Compress: tt.compress})
+			if err != nil {
+				t.Fatalf("NewRotatingFile() error = %v", err)
+			}
+
+			// rotated in the same ms, each backup must keep its own name
+			for i := range 5 {
+				rotatingFile.Write([]byte{byte('0' + i)})
+
+				err := rotatingFile.Rotate()
+				if err != nil {
+					t.Fatalf("Rotate() error = %v", err)
+				}
+			}
+
+			// waits for the cleanups
+			rotatingFile.Close()
+
+			backups, err := rotatingFile.backups()
+			if err != nil {
+				t.Fatalf("listing the rotated files: %v", err)
+			}
+
+			var got []string
+			for _, backup := range backups {
+				if strings.HasSuffix(backup.path, ".gz") != tt.compress {
+					t.Errorf("backup %v compression, want %v", backup.path, tt.compress)
+				}
+				if !tt.compress {
+					got = append(got, readFile(t, backup.path))
+				}
+			}
+
+			if tt.compress {
+				if len(backups) != len(tt.wantBackups) {
+					t.Errorf("kept %v backups, want %v", len(backups), len(tt.wantBackups))
+				}
+				return
+			}
+
+			if !slices.Equal(got, tt.wantBackups) {
+				t.Errorf("kept backups %q, want %q", got, tt.wantBackups)
+			}
+		})
+	}
+}
+
+func TestParseRotatedFile(t *testing.T) {
+	tests := []struct {
+		suffix       string
+		wantOk       bool
+		wantSequence int
+	}{
+		{"20240101T000000.000", true, 0},
+		{"20240101T000000.000-3", true, 3},
+		{"20240101T000000.000-0", false, 0},
+		{"20240101T000000.000-x", false, 0},
+		{"error", false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.suffix, func(t *testing.T) {
+			backup, ok := parseRotatedFile(tt.suffix)
+			if ok != tt.wantOk || backup.sequence != tt.wantSequence {
+				t.Errorf("parseRotatedFile() = %v, %v, want sequence %v, %v", backup.sequence, ok, tt.wantSequence, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestRotatingFileReopen(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app.log")
+	movedPath := filepath.Join(dir, "app.log.1")
+
+	rotatingFile := newTestRotatingFile(t, RotatingFileOptions{Path: path, ReopenOnHangup: true})
+	rotatingFile.Write([]byte("before"))
+
+	// an external logrotate moves the file, then signals the process
+	err := os.Rename(path, movedPath)
+	if err != nil {
+		t.Fatalf("moving the log file: %v", err)
+	}
+
+	err = syscall.Kill(os.Getpid(), syscall.SIGHUP)
+	if err != nil {
+		t.Fatalf("sending SIGHUP: %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for !fileExists(path) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	rotatingFile.Write([]byte("after"))
+
+	if got := readFile(t, movedPath); got != "before" {
+		t.Errorf("moved file = %q, want before", got)
+	}
+	if got := readFile(t, path); got != "after" {
+		t.Errorf("reopened file = %q, want after", got)
+	}
+}
+
+func TestRotatingFileClose(t *testing.T) {
+	rotatingFile := newTestRotatingFile(t, RotatingFileOptions{Path: filepath.Join(t.TempDir(), "app.log"), ReopenOnHangup: true})
+
+	for range 2 {
+		err := rotatingFile.Close()
+		if err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	}
+
+	select {
+	case <-rotatingFile.done:
+	default:
+		t.Errorf("SIGHUP goroutine not stopped by Close")
+	}
+
+	_, err := rotatingFile.Write([]byte("closed"))
+	if err != os.ErrClosed {
+		t.Errorf("Write() after Close error = %v, want os.ErrClosed", err)
+	}
+	if err := rotatingFile.Reopen(); err != os.ErrClosed {
+		t.Errorf("Reopen() after Close error = %v, want os.ErrClosed", err)
+	}
+}
@@ -0,0 +1,147 @@
+package my_logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Default time the sampling counts are reset at
+const default_sampling_ms_interval = 1000
+
+// SamplingRule logs the First records of a message in each interval, then 1 in Thereafter.
+// A Thereafter of 0 drops the rest of the records of the interval.
+type SamplingRule struct {
+	First      int
+	Thereafter int
+}
+
+// SamplingOptions sets which records are sampled. Records are counted per level and
+// message. A record uses the rule of its message, else of its level, else the default one.
+// A nil rule disables the sampling of the records it matches. Error and above records are
+// never sampled.
+type SamplingOptions struct {
+	// Time the counts are reset at, in ms
+	Interval *int
+
+	Default  *SamplingRule
+	Levels   map[string]*SamplingRule
+	Messages map[string]*SamplingRule
+}
+
+type samplingKey struct {
+	level slog.Level
+	msg   string
+}
+
+// sampler holds the counts of a logger, shared by all its copies
+type sampler struct {
+	options  SamplingOptions
+	levels   map[slog.Level]*SamplingRule
+	interval time.Duration
+
+	mu          sync.Mutex
+	counts      map[samplingKey]int
+	windowStart time.Time
+}
+
+func newSampler(opts *SamplingOptions) (*sampler, error) {
+	s := &sampler{
+		options:  *opts,
+		levels:   make(map[slog.Level]*SamplingRule, len(opts.Levels)),
+		interval: time.Millisecond * default_sampling_ms_interval,
+		counts:   map[samplingKey]int{},
+	}
+
+	if opts.Interval != nil {
+		if *opts.Interval <= 0 {
+			return nil, errors.New("sampling interval must be positive")
+		}
+		s.interval = time.Millisecond * time.Duration(*opts.Interval)
+	}
+
+	for name, rule := range opts.Levels {
+		level, err := ParseLevel(name)
+		if err != nil {
+			return nil, fmt.Errorf("sampling: %w", err)
+		}
+		if level >= LevelError {
+			return nil, fmt.Errorf("sampling: %v records are never sampled", name)
+		}
+		s.levels[level.Level()] = rule
+	}
+
+	return s, nil
+}
+
+func (s *sampler) rule(r slog.Record) *SamplingRule {
+	if rule, ok := s.options.Messages[r.Message]; ok {
+		return rule
+	}
+
+	if rule, ok := s.levels[r.Level]; ok {
+		return rule
+	}
+
+	return s.options.Default
+}
+
+// sample reports whether r is logged
+func (s *sampler) sample(r slog.Record) bool {
+	if r.Level >= slog.LevelError {
+		return true
+	}
+
+	rule := s.rule(r)
+	if rule == nil {
+		return true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.windowStart) >= s.interval {
+		s.windowStart = now
+		clear(s.counts)
+	}
+
+	key := samplingKey{r.Level, r.Message}
+	s.counts[key]++
+	count := s.counts[key]
+
+	if count <= rule.First {
+		return true
+	}
+
+	return rule.Thereafter > 0 && (count-rule.First)%rule.Thereafter == 0
+}
+
+// samplingHandler drops the records left out by its sampler
+type samplingHandler struct {
+	next    slog.Handler
+	sampler *sampler
+}
+
+func (h *samplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *samplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.sampler.sample(r) {
+		return nil
+	}
+
+	return h.next.Handle(ctx, r)
+}
+
+func (h *samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &samplingHandler{next: h.next.WithAttrs(attrs), sampler: h.sampler}
+}
+
+func (h *samplingHandler) WithGroup(name string) slog.Handler {
+	return &samplingHandler{next: h.next.WithGroup(name), sampler: h.sampler}
+}
@@ -0,0 +1,123 @@
+package my_logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// loggedRecords decodes the json records written to output
+func loggedRecords(t *testing.T, output *bytes.Buffer) []map[string]any {
+	t.Helper()
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+		if line == "" {
+			continue
+		}
+
+		var record map[string]any
+		err := json.Unmarshal([]byte(line), &record)
+		if err != nil {
+			t.Fatalf("decoding record %q: %v", line, err)
+		}
+		records = append(records, record)
+	}
+
+	return records
+}
+
+func TestSampling(t *testing.T) {
+	tests := []struct {
+		name     string
+		sampling SamplingOptions
+		level    string
+		msg      string
+		times    int
+		want     int
+	}{
+		{"first records", SamplingOptions{Default: &SamplingRule{First: 2}}, "info", "tick", 5, 2},
+		{"thereafter", SamplingOptions{Default: &SamplingRule{First: 1, Thereafter: 2}}, "info", "tick", 5, 3},
+		{"level rule", SamplingOptions{Levels: map[string]*SamplingRule{"warn": {First: 1}}}, "warn", "tick", 5, 1},
+		{"message rule over level rule", SamplingOptions{
+			Levels:   map[string]*SamplingRule{"info": {First: 1}},
+			Messages: map[string]*SamplingRule{"tick": nil},
+		}, "info", "tick", 5, 5},
+		{"errors are never sampled", SamplingOptions{Default: &SamplingRule{First: 1}}, "error", "db down", 5, 5},
+		{"critical records are never sampled", SamplingOptions{Default: &SamplingRule{First: 1}}, "critical", "db down", 5, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var output bytes.Buffer
+
+			logger, err := NewLogger(&LoggerOptions{Level: "trace", Output: &output, Sampling: &tt.sampling})
+			if err != nil {
+				t.Fatalf("NewLogger() error = %v", err)
+			}
+
+			for range tt.times {
+				logger.Log(context.Background(), tt.level, tt.msg)
+			}
+
+			if got := len(loggedRecords(t, &output)); got != tt.want {
+				t.Errorf("logged %v records, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSamplingRejectsErrorLevels(t *testing.T) {
+	_, err := NewLogger(&LoggerOptions{
+		Level:    "info",
+		Output:   &bytes.Buffer{},
+		Sampling: &SamplingOptions{Levels: map[string]*SamplingRule{"error": {First: 1}}},
+	})
+	if err == nil {
+		t.Errorf("NewLogger() accepted an error level sampling rule")
+	}
+}
+
+func TestDedupSummaryIsNotSampled(t *testing.T) {
+	var output bytes.Buffer
+	window := 60 * 60 * 1000
+
+	logger, err := NewLogger(&LoggerOptions{
+		Level:  "info",
+		Output: &output,
+		Dedup:  &DedupOptions{Window: &window, Level: "warn"},
+		// lets both slow queries through, but would drop a third record, the summary
+		Sampling: &SamplingOptions{Messages: map[string]*SamplingRule{"slow query": {First: 2}}},
+	})
+	if err != nil {
+		t.Fatalf("NewLogger() error = %v", err)
+	}
+
+	logger.Warn("slow query")
+	logger.Warn("slow query")
+	logger.Error("db down")
+	logger.Error("db down")
+	logger.Error("db down")
+
+	// ends the windows, logging the repeat counts
+	err = logger.Shutdown()
+	if err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	repeated := map[string]float64{}
+	for _, record := range loggedRecords(t, &output) {
+		if count, ok := record["repeated"].(float64); ok {
+			repeated[record["msg"].(string)] = count
+		}
+	}
+
+	if repeated["slow query"] != 1 {
+		t.Errorf("slow query repeat count = %v, want 1 in %v", repeated["slow query"], output.String())
+	}
+	if repeated["db down"] != 2 {
+		t.Errorf("db down repeat count = %v, want 2 in %v", repeated["db down"], output.String())
+	}
+}
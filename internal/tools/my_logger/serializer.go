@@ -10,6 +10,18 @@ type Serializer interface {
 	Serialize(any) (slog.Attr, bool)
 }
 
+// SerializerFunc adapts a function to the Serializer interface
+type SerializerFunc func(any) (slog.Attr, bool)
+
+func (f SerializerFunc) Serialize(attr any) (slog.Attr, bool) {
+	return f(attr)
+}
+
+// Loggable is implemented by types that know how to serialize themselves
+type Loggable interface {
+	LogAttr() slog.Attr
+}
+
 type DefaultSerializers struct{}
 
 type HttpResponseLogData struct {
@@ -20,6 +32,9 @@ type HttpResponseLogData struct {
 
 func (d *DefaultSerializers) Serialize(attr any) (slog.Attr, bool) {
 	switch a := attr.(type) {
+	case Loggable:
+		return a.LogAttr(), true
+
 	case error:
 		return serializeError(a), true
 
@@ -34,10 +49,6 @@ func (d *DefaultSerializers) Serialize(attr any) (slog.Attr, bool) {
 	}
 }
 
-func serializeError(e error) slog.Attr {
-	return slog.Group("err", slog.Any("msg", e.Error()))
-}
-
 func serializeHttpRequest(r *http.Request) slog.Attr {
 	return slog.Group("req",
 		slog.Any("method", r.Method),
@@ -0,0 +1,60 @@
+package my_logger
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+)
+
+type registeredSerializer struct {
+	priority   int
+	serializer Serializer
+}
+
+// SerializerRegistry chains serializers registered by the application, highest priority
+// first, falling back to the DefaultSerializers. Use it as LoggerOptions.Serializer to add
+// serializers for the application types without re-implementing the default ones.
+type SerializerRegistry struct {
+	serializers []registeredSerializer
+	fallback    Serializer
+	mu          sync.RWMutex
+}
+
+func NewSerializerRegistry() *SerializerRegistry {
+	return &SerializerRegistry{fallback: &DefaultSerializers{}}
+}
+
+// Register adds s to the chain. Serializers with the same priority run in registration order.
+func (r *SerializerRegistry) Register(priority int, s Serializer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.serializers = append(r.serializers, registeredSerializer{priority, s})
+	slices.SortStableFunc(r.serializers, func(a, b registeredSerializer) int {
+		return b.priority - a.priority
+	})
+}
+
+// RegisterType adds a serializer for the values of type T to the registry
+func RegisterType[T any](r *SerializerRegistry, priority int, fn func(T) slog.Attr) {
+	r.Register(priority, SerializerFunc(func(attr any) (slog.Attr, bool) {
+		if a, ok := attr.(T); ok {
+			return fn(a), true
+		}
+
+		return slog.Attr{}, false
+	}))
+}
+
+func (r *SerializerRegistry) Serialize(attr any) (slog.Attr, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, s := range r.serializers {
+		if serializedAttr, ok := s.serializer.Serialize(attr); ok {
+			return serializedAttr, true
+		}
+	}
+
+	return r.fallback.Serialize(attr)
+}
@@ -0,0 +1,54 @@
+package my_logger
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+)
+
+type testUser struct {
+	ID int
+}
+
+func TestSerializerRegistry(t *testing.T) {
+	registry := NewSerializerRegistry()
+
+	RegisterType(registry, 1, func(_ testUser) slog.Attr {
+		return slog.String("user", "low priority")
+	})
+	RegisterType(registry, 10, func(u testUser) slog.Attr {
+		return slog.Int("user", u.ID)
+	})
+	RegisterType(registry, 10, func(_ testUser) slog.Attr {
+		return slog.String("user", "registered later")
+	})
+	RegisterType(registry, 5, func(e error) slog.Attr {
+		return slog.String("err", "custom "+e.Error())
+	})
+
+	tests := []struct {
+		name    string
+		value   any
+		wantKey string
+		want    string
+		wantOk  bool
+	}{
+		{"highest priority first, then registration order", testUser{ID: 7}, "user", "7", true},
+		{"registered type over the defaults", errors.New("boom"), "err", "custom boom", true},
+		{"default serializer fallback", &HttpResponseLogData{StatusCode: 200, Path: "/"}, "res", "[status=200 path=/ time=0s]", true},
+		{"unknown type", 42, "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attr, ok := registry.Serialize(tt.value)
+			if ok != tt.wantOk {
+				t.Fatalf("Serialize() ok = %v, want %v", ok, tt.wantOk)
+			}
+
+			if attr.Key != tt.wantKey || (ok && attr.Value.String() != tt.want) {
+				t.Errorf("Serialize() = %v=%v, want %v=%v", attr.Key, attr.Value, tt.wantKey, tt.want)
+			}
+		})
+	}
+}
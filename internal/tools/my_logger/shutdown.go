@@ -0,0 +1,141 @@
+package my_logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Default fatal record handling
+const (
+	default_fatal_exit_code  = 1
+	default_fatal_ms_timeout = 5 * 1000
+)
+
+// ShutdownHook is run by Logger.Shutdown, before the outputs are flushed
+type ShutdownHook func() error
+
+// shutdown holds the outputs and hooks of a Logger, shared by all its copies
+type shutdown struct {
+	mu      sync.Mutex
+	outputs []io.Writer
+	hooks   []ShutdownHook
+
+	once sync.Once
+	err  error
+
+	exit     func(code int)
+	exitCode int
+	timeout  time.Duration
+}
+
+func newShutdown(opts *LoggerOptions) *shutdown {
+	s := &shutdown{
+		exit:     opts.ExitFunc,
+		exitCode: default_fatal_exit_code,
+		timeout:  time.Millisecond * default_fatal_ms_timeout,
+	}
+
+	if s.exit == nil {
+		s.exit = os.Exit
+	}
+
+	if opts.FatalExitCode != nil {
+		s.exitCode = *opts.FatalExitCode
+	}
+
+	if opts.FatalTimeout != nil {
+		s.timeout = time.Millisecond * time.Duration(*opts.FatalTimeout)
+	}
+
+	return s
+}
+
+// RegisterOutput adds an output flushed by Shutdown, besides LoggerOptions.Output
+func (l *Logger) RegisterOutput(output io.Writer) {
+	l.shutdown.mu.Lock()
+	defer l.shutdown.mu.Unlock()
+
+	l.shutdown.outputs = append(l.shutdown.outputs, output)
+}
+
+// OnShutdown registers a hook run by Shutdown and before exiting on a fatal record, e.g.
+// to release resources whose deferred cleanup os.Exit would skip
+func (l *Logger) OnShutdown(hook ShutdownHook) {
+	l.shutdown.mu.Lock()
+	defer l.shutdown.mu.Unlock()
+
+	l.shutdown.hooks = append(l.shutdown.hooks, hook)
+}
+
+// Shutdown runs the shutdown hooks, in reverse registration order, then flushes the
+// outputs: closes the ones implementing io.Closer and syncs the files. Only the first call
+// does it, the next ones return the same error.
+func (l *Logger) Shutdown() error {
+	l.shutdown.once.Do(func() {
+		var errs []error
+
+		l.shutdown.mu.Lock()
+		hooks := l.shutdown.hooks
+		outputs := l.shutdown.outputs
+		l.shutdown.mu.Unlock()
+
+		for i := len(hooks) - 1; i >= 0; i-- {
+			err := hooks[i]()
+			if err != nil {
+				l.Error("shutdown hook failed", err)
+				errs = append(errs, err)
+			}
+		}
+
+		for _, output := range outputs {
+			err := flushOutput(output)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("flushing log output: %w", err))
+			}
+		}
+
+		l.shutdown.err = errors.Join(errs...)
+	})
+
+	return l.shutdown.err
+}
+
+// exitAfterFatal shuts the logger down and exits, without waiting more than the fatal
+// timeout for the hooks and outputs
+func (l *Logger) exitAfterFatal() {
+	done := make(chan struct{})
+
+	go func() {
+		err := l.Shutdown()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "logger shutdown error: %v\n", err)
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(l.shutdown.timeout):
+		fmt.Fprintln(os.Stderr, "logger shutdown timed out")
+	}
+
+	l.shutdown.exit(l.shutdown.exitCode)
+}
+
+func flushOutput(output io.Writer) error {
+	switch out := output.(type) {
+	case *os.File:
+		if out == os.Stdout || out == os.Stderr {
+			return nil
+		}
+		return out.Sync()
+	case io.Closer:
+		return out.Close()
+	default:
+		return nil
+	}
+}
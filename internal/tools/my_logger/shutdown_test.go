@@ -0,0 +1,109 @@
+package my_logger
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// closeRecorder is a log output recording whether it was closed
+type closeRecorder struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestFatalShutsDownAndExits(t *testing.T) {
+	exitCode3 := 3
+
+	tests := []struct {
+		name          string
+		fatalExitCode *int
+		wantCode      int
+	}{
+		{"default exit code", nil, 1},
+		{"configured exit code", &exitCode3, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var exitCodes []int
+			var calls []string
+			output := &closeRecorder{}
+
+			logger, err := NewLogger(&LoggerOptions{
+				Level:         "info",
+				Output:        output,
+				ExitFunc:      func(code int) { exitCodes = append(exitCodes, code) },
+				FatalExitCode: tt.fatalExitCode,
+			})
+			if err != nil {
+				t.Fatalf("NewLogger() error = %v", err)
+			}
+
+			logger.OnShutdown(func() error {
+				calls = append(calls, "first")
+				return nil
+			})
+			logger.OnShutdown(func() error {
+				calls = append(calls, "second")
+				return errors.New("hook failed")
+			})
+
+			logger.Fatal("cannot start")
+
+			if len(exitCodes) != 1 || exitCodes[0] != tt.wantCode {
+				t.Errorf("exited with %v, want [%v]", exitCodes, tt.wantCode)
+			}
+			if strings.Join(calls, ",") != "second,first" {
+				t.Errorf("hooks ran as %v, want second,first", calls)
+			}
+			if !output.closed {
+				t.Errorf("output not flushed before exiting")
+			}
+
+			logged := output.String()
+			if !strings.Contains(logged, `"level":"FATAL"`) || !strings.Contains(logged, "cannot start") {
+				t.Errorf("fatal record missing from %q", logged)
+			}
+			if !strings.Contains(logged, "hook failed") {
+				t.Errorf("hook error not logged in %q", logged)
+			}
+
+			// a second shutdown is a no-op returning the same error
+			err = logger.Shutdown()
+			if err == nil || len(calls) != 2 {
+				t.Errorf("Shutdown() = %v after %v hook calls, want the first error and no rerun", err, len(calls))
+			}
+		})
+	}
+}
+
+func TestFromContextFallback(t *testing.T) {
+	logger := FromContext(context.Background())
+	if logger.shutdown == nil || logger.levels == nil {
+		t.Fatalf("fallback logger built without shutdown or levels")
+	}
+
+	var exitCodes []int
+	exit := logger.shutdown.exit
+	logger.shutdown.exit = func(code int) { exitCodes = append(exitCodes, code) }
+	defer func() { logger.shutdown.exit = exit }()
+
+	logger.Fatal("fallback fatal")
+
+	if len(exitCodes) != 1 || exitCodes[0] != default_fatal_exit_code {
+		t.Errorf("exited with %v, want [%v]", exitCodes, default_fatal_exit_code)
+	}
+
+	withLogger := NewContext(context.Background(), logger.With("k", "v"))
+	if FromContext(withLogger) == logger {
+		t.Errorf("FromContext() ignored the context logger")
+	}
+}
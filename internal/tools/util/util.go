@@ -1,3 +0,0 @@
-package util
-
-type CtxKey string